@@ -0,0 +1,168 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"sync"
+	"time"
+	"trelligo/pkg/dfplayer"
+	"trelligo/pkg/dfplayer/dfplayersim"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/gonybox"
+	"trelligo/pkg/hyst"
+	"trelligo/pkg/neotrellis"
+	"trelligo/pkg/player"
+	"trelligo/pkg/seesaw/seesawsim"
+	"trelligo/pkg/shims/rand"
+)
+
+// config of the simulated hardware
+type config struct {
+	// folders the number of files in each folder of the SD card, starting at folder 1
+	folders     []uint16
+	trackLength time.Duration
+	seed        int64
+	// now is the clock of the player and the DFPlayer, the tracks play by it
+	now func() time.Time
+}
+
+// jukebox runs the player the firmware runs, see gonybox.NewPlayer, with simulated peripherals
+type jukebox struct {
+	trellis *seesawsim.Device
+	dfp     *dfplayersim.Device
+	knob    *knob
+	cards   *cardReader
+	player  *player.Player
+}
+
+func newJukebox(cfg config) (*jukebox, error) {
+	j := &jukebox{
+		trellis: seesawsim.New(neotrellis.DefaultNeoTrellisAddress),
+		dfp:     dfplayersim.New(cfg.now),
+		knob:    &knob{},
+		cards:   &cardReader{},
+	}
+	for i, files := range cfg.folders {
+		j.dfp.AddFolder(uint8(i+1), files)
+	}
+	j.dfp.SetTrackLength(cfg.trackLength)
+	j.knob.Turn(10)
+
+	nt, err := neotrellis.New(j.trellis, 0)
+	if err != nil {
+		return nil, err
+	}
+	p, err := gonybox.NewPlayer(gonybox.Config{
+		Trellis:  nt,
+		DFPlayer: dfplayer.NewPlayer(j.dfp),
+		Knob:     hyst.New(j.knob, 1500),
+		Busy:     j.dfp.BusyPin(),
+		Rand:     rand.New(rand.NewSource(cfg.seed)),
+		Now:      cfg.now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	j.player = p
+	return j, nil
+}
+
+// Step runs the player once, cards placed on the reader play their folder
+func (j *jukebox) Step() error {
+	if card, ok := j.cards.Poll(); ok {
+		err := j.player.PlayFolder(card)
+		if err != nil {
+			return err
+		}
+	}
+	return j.player.Process()
+}
+
+func (j *jukebox) Press(x, y uint8) {
+	j.trellis.Press(neotrellis.PositionFromXY(x, y).KeyID())
+}
+
+func (j *jukebox) Release(x, y uint8) {
+	j.trellis.Release(neotrellis.PositionFromXY(x, y).KeyID())
+}
+
+// Pixels returns the colors the NeoTrellis shows, after gamma correction
+func (j *jukebox) Pixels() draw.Buffer4x4 {
+	var b draw.Buffer4x4
+	shown := j.trellis.ShownPixels()
+	for x := uint8(0); x < 4; x++ {
+		for y := uint8(0); y < 4; y++ {
+			o := int(neotrellis.PositionFromXY(x, y).PixelOffset()) * 3
+			if o+2 < len(shown) {
+				b.Set(x, y, draw.RGB{R: shown[o+1], G: shown[o], B: shown[o+2]})
+			}
+		}
+	}
+	return b
+}
+
+// knob simulates the potentiometer on the ADC
+type knob struct {
+	mu sync.Mutex
+	v  uint16
+}
+
+var _ hyst.Getter = &knob{}
+
+func (k *knob) Get() uint16 {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.v
+}
+
+// Turn sets the ADC to the middle of the volume step, in the range [0,30]
+func (k *knob) Turn(step int) {
+	step = max(0, min(step, 30))
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	// hyst maps steps of 2048 starting at 2047
+	k.v = uint16(2047 + step*2048 + 1024)
+}
+
+// Step returns the volume step the knob is at
+func (k *knob) Step() int {
+	return (int(k.Get()) - 2047) / 2048
+}
+
+// cardReader simulates an RFID reader, each card plays the folder of its number
+type cardReader struct {
+	mu     sync.Mutex
+	card   uint8
+	placed bool
+}
+
+func (c *cardReader) Place(card uint8) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.card = card
+	c.placed = true
+}
+
+func (c *cardReader) Remove() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.card = 0
+}
+
+// Card returns the card on the reader, 0 if there is none
+func (c *cardReader) Card() uint8 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.card
+}
+
+// Poll returns a newly placed card
+func (c *cardReader) Poll() (uint8, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.placed || c.card == 0 {
+		return 0, false
+	}
+	c.placed = false
+	return c.card, true
+}
@@ -0,0 +1,79 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"io"
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/debug"
+	"trelligo/pkg/dfplayer"
+)
+
+func newTestJukebox(t *testing.T) (*jukebox, *time.Time) {
+	debug.SetOutput(io.Discard)
+	now := time.Unix(0, 0)
+	j, err := newJukebox(config{
+		folders:     []uint16{2, 3},
+		trackLength: time.Minute,
+		seed:        1,
+		now:         func() time.Time { return now },
+	})
+	be.NoError(t, err)
+	be.NoError(t, j.Step())
+	return j, &now
+}
+
+// tap taps a key and moves the clock on until another tap wouldn't be a double tap
+func tap(t *testing.T, j *jukebox, now *time.Time, x, y uint8) {
+	j.Press(x, y)
+	be.NoError(t, j.Step())
+	j.Release(x, y)
+	be.NoError(t, j.Step())
+	*now = now.Add(350 * time.Millisecond)
+}
+
+func TestJukebox_Folders(t *testing.T) {
+	j, now := newTestJukebox(t)
+	be.Equal(t, j.dfp.Volume(), 6)
+
+	// folder 2 is the second key of the top row
+	tap(t, j, now, 1, 3)
+	folder, file := j.dfp.Track()
+	be.Equal(t, folder, 2)
+	be.Equal(t, file, 1)
+
+	// the player moves on when the track finished
+	*now = now.Add(time.Minute)
+	be.NoError(t, j.Step())
+	be.NoError(t, j.Step())
+	_, file = j.dfp.Track()
+	be.Equal(t, file, 2)
+
+	// folder 1 starts at its beginning, coming back resumes folder 2
+	tap(t, j, now, 0, 3)
+	tap(t, j, now, 1, 3)
+	folder, file = j.dfp.Track()
+	be.Equal(t, folder, 2)
+	be.Equal(t, file, 2)
+
+	// tapping the playing folder pauses
+	tap(t, j, now, 1, 3)
+	be.Equal(t, j.dfp.State(), dfplayer.StatePaused)
+}
+
+func TestJukebox_Card(t *testing.T) {
+	j, _ := newTestJukebox(t)
+
+	j.cards.Place(1)
+	be.NoError(t, j.Step())
+	folder, _ := j.dfp.Track()
+	be.Equal(t, folder, 1)
+	be.Equal(t, j.dfp.State(), dfplayer.StatePlaying)
+
+	// the knob is limited by the volume policy
+	j.knob.Turn(30)
+	be.NoError(t, j.Step())
+	be.Equal(t, j.dfp.Volume(), 24)
+}
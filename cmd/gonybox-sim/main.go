@@ -0,0 +1,230 @@
+//go:build !tinygo
+
+// Command gonybox-sim runs the jukebox on the host with a simulated NeoTrellis, DFPlayer, potentiometer and RFID
+// reader, e.g.
+//
+//	go run ./cmd/gonybox-sim
+//	go run ./cmd/gonybox-sim -folders 3,12,1 -track 10s
+//
+// Commands are read line by line, see help.
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+	"trelligo/pkg/debug"
+)
+
+// keyRows the keyboard keys of the NeoTrellis keys, from the top row down
+var keyRows = [4]string{"1234", "qwer", "asdf", "zxcv"}
+
+const tapFor = 50 * time.Millisecond
+const longPressFor = time.Second
+
+const help = `keys:      1234 / qwer / asdf / zxcv tap the keys, upper case long-presses, e.g. "q" or "Q"
+long:      long <keys> long-presses keys of any row, e.g. "long 1"
+hold:      hold <keys> <duration> holds keys together, e.g. "hold xc 3s" unlocks the settings
+knob:      knob <0-30> turns the volume knob
+card:      card <n> places the card of folder n on the reader, card removes it
+sd:        sd inserts or removes the SD card
+skip:      skip fast-forwards the current track to its end
+quit:      quit exits
+`
+
+func main() {
+	folders := flag.String("folders", "5,5,5,5,5,5,5,5,5", "number of files in each folder of the SD card")
+	track := flag.Duration("track", 30*time.Second, "how long each track plays")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed of the random number generator")
+	logFile := flag.String("log", "gonybox-sim.log", "file to write the player log to")
+	flag.Parse()
+
+	files, err := parseFolders(*folders)
+	if err != nil {
+		fail(err)
+	}
+
+	logOut, err := os.Create(*logFile)
+	if err != nil {
+		fail(err)
+	}
+	defer logOut.Close()
+	debug.SetOutput(logOut)
+
+	c := &clock{}
+	j, err := newJukebox(config{folders: files, trackLength: *track, seed: *seed, now: c.Now})
+	if err != nil {
+		fail(err)
+	}
+
+	quit := make(chan struct{})
+	go func() {
+		readCommands(os.Stdin, os.Stdout, j, c)
+		close(quit)
+	}()
+
+	ui := newScreen(os.Stdout)
+	defer ui.Close()
+	fmt.Print(help)
+	for {
+		select {
+		case <-quit:
+			return
+		default:
+		}
+		err = j.Step()
+		if err != nil {
+			fail(err)
+		}
+		ui.Draw(j)
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// readCommands runs the commands until quit or the end of input, see help
+func readCommands(r io.Reader, w io.Writer, j *jukebox, c *clock) {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var err error
+		switch fields[0] {
+		case "quit", "exit":
+			return
+		case "help":
+			fmt.Fprint(w, help)
+		case "hold":
+			if len(fields) != 3 {
+				err = fmt.Errorf("usage: hold <keys> <duration>")
+				break
+			}
+			var d time.Duration
+			d, err = time.ParseDuration(fields[2])
+			if err == nil {
+				err = hold(j, fields[1], d)
+			}
+		case "long":
+			if len(fields) != 2 {
+				err = fmt.Errorf("usage: long <keys>")
+				break
+			}
+			err = tapAll(j, fields[1], true)
+		case "knob":
+			if len(fields) != 2 {
+				err = fmt.Errorf("usage: knob <0-30>")
+				break
+			}
+			var v int
+			v, err = strconv.Atoi(fields[1])
+			if err == nil {
+				j.knob.Turn(v)
+			}
+		case "card":
+			if len(fields) == 1 {
+				j.cards.Remove()
+				break
+			}
+			var n int
+			n, err = strconv.Atoi(fields[1])
+			if err == nil && (n < 1 || n > 99) {
+				err = fmt.Errorf("cards are numbered 1 to 99")
+			}
+			if err == nil {
+				j.cards.Place(uint8(n))
+			}
+		case "sd":
+			j.dfp.SetCard(!j.dfp.Card())
+		case "skip":
+			c.Skip(j.dfp)
+		default:
+			err = tapAll(j, fields[0], false)
+		}
+		if err != nil {
+			fmt.Fprintln(w, err)
+		}
+	}
+}
+
+// tapAll taps the keys one after the other, upper case keys or all keys if long are long-pressed.
+// The digits have no upper case, so they can only be long-pressed with long.
+func tapAll(j *jukebox, keys string, long bool) error {
+	for _, k := range keys {
+		if _, _, ok := keyOf(k); !ok {
+			return fmt.Errorf("unknown key %q, try help", k)
+		}
+	}
+	for _, k := range keys {
+		x, y, _ := keyOf(k)
+		d := tapFor
+		if long || k != toLower(k) {
+			d = longPressFor
+		}
+		j.Press(x, y)
+		time.Sleep(d)
+		j.Release(x, y)
+		// a quick second tap would be a double tap
+		time.Sleep(350 * time.Millisecond)
+	}
+	return nil
+}
+
+// hold presses the keys together for d
+func hold(j *jukebox, keys string, d time.Duration) error {
+	var pressed [][2]uint8
+	for _, k := range keys {
+		x, y, ok := keyOf(k)
+		if !ok {
+			return fmt.Errorf("unknown key %q, try help", k)
+		}
+		pressed = append(pressed, [2]uint8{x, y})
+	}
+	for _, p := range pressed {
+		j.Press(p[0], p[1])
+	}
+	time.Sleep(d)
+	for _, p := range pressed {
+		j.Release(p[0], p[1])
+	}
+	return nil
+}
+
+func keyOf(k rune) (uint8, uint8, bool) {
+	k = toLower(k)
+	for row, keys := range keyRows {
+		if i := strings.IndexRune(keys, k); i >= 0 {
+			return uint8(i), uint8(3 - row), true
+		}
+	}
+	return 0, 0, false
+}
+
+func toLower(r rune) rune {
+	if 'A' <= r && r <= 'Z' {
+		return r + 'a' - 'A'
+	}
+	return r
+}
+
+func parseFolders(s string) ([]uint16, error) {
+	var files []uint16
+	for _, f := range strings.Split(s, ",") {
+		n, err := strconv.ParseUint(strings.TrimSpace(f), 10, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number of files %q: %w", f, err)
+		}
+		files = append(files, uint16(n))
+	}
+	return files, nil
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
@@ -0,0 +1,114 @@
+//go:build !tinygo
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"trelligo/pkg/dfplayer"
+	"trelligo/pkg/dfplayer/dfplayersim"
+)
+
+// statusRows the rows at the top of the terminal the jukebox is drawn in, commands scroll below
+const statusRows = 8
+
+// screen draws the keys and the state of the peripherals at the top of the terminal
+type screen struct {
+	w    *bufio.Writer
+	last string
+}
+
+func newScreen(w io.Writer) *screen {
+	s := &screen{w: bufio.NewWriter(w)}
+	// clear, keep the status rows out of the scrolling region and move below them
+	fmt.Fprintf(s.w, "\x1b[2J\x1b[%d;r\x1b[%d;1H", statusRows+1, statusRows+1)
+	s.w.Flush()
+	return s
+}
+
+// Close restores the scrolling region
+func (s *screen) Close() {
+	fmt.Fprint(s.w, "\x1b[r")
+	s.w.Flush()
+}
+
+// Draw redraws the status rows if anything changed
+func (s *screen) Draw(j *jukebox) {
+	status := s.status(j)
+	pixels := j.Pixels()
+	frame := fmt.Sprint(pixels) + status
+	if frame == s.last {
+		return
+	}
+	s.last = frame
+
+	// save the cursor and draw at the top
+	fmt.Fprint(s.w, "\x1b7\x1b[H")
+	for row, keys := range keyRows {
+		y := 3 - row
+		for x, k := range keys {
+			c := pixels[4*x+y]
+			fmt.Fprintf(s.w, "\x1b[48;2;%d;%d;%dm\x1b[38;2;%d;%d;%dm %c ", c.R, c.G, c.B, 0x80^c.R, 0x80^c.G, 0x80^c.B, k)
+		}
+		fmt.Fprint(s.w, "\x1b[0m\x1b[K\n")
+	}
+	fmt.Fprint(s.w, status)
+	fmt.Fprint(s.w, "\x1b8")
+	s.w.Flush()
+}
+
+func (s *screen) status(j *jukebox) string {
+	var b strings.Builder
+	folder, file := j.dfp.Track()
+	switch j.dfp.State() {
+	case dfplayer.StatePlaying:
+		fmt.Fprintf(&b, "playing %02d/%03d, %s left", folder, file, j.dfp.Remaining().Round(time.Second))
+	case dfplayer.StatePaused:
+		fmt.Fprintf(&b, "paused %02d/%03d", folder, file)
+	default:
+		fmt.Fprint(&b, "stopped")
+	}
+	if j.dfp.Sleeping() {
+		fmt.Fprint(&b, ", sleeping")
+	}
+	fmt.Fprintf(&b, ", volume %d", j.dfp.Volume())
+	if j.dfp.Looping() {
+		fmt.Fprint(&b, ", looping")
+	}
+	fmt.Fprint(&b, "\x1b[K\n")
+
+	fmt.Fprintf(&b, "knob %d", j.knob.Step())
+	if card := j.cards.Card(); card != 0 {
+		fmt.Fprintf(&b, ", card %d", card)
+	}
+	if !j.dfp.Card() {
+		fmt.Fprint(&b, ", no SD card")
+	}
+	fmt.Fprint(&b, "\x1b[K\n")
+	fmt.Fprint(&b, "\x1b[K\n\x1b[K\n")
+	return b.String()
+}
+
+// clock is the clock of the simulated DFPlayer, skipping moves it ahead
+type clock struct {
+	mu   sync.Mutex
+	skew time.Duration
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return time.Now().Add(c.skew)
+}
+
+// Skip moves the clock to the end of the current track
+func (c *clock) Skip(d *dfplayersim.Device) {
+	r := d.Remaining()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.skew += r
+}
@@ -0,0 +1,94 @@
+// Command preview renders the animations on the host, in the terminal or as GIF/PNG, e.g.
+//
+//	go run ./cmd/preview -anim matrix
+//	go run ./cmd/preview -anim rainbow -duration 5s -o rainbow.gif
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/draw/animations"
+	"trelligo/pkg/draw/preview"
+	"trelligo/pkg/shims/rand"
+)
+
+func main() {
+	name := flag.String("anim", "matrix", "animation: matrix, blink, rainbow, life, pulse or text")
+	text := flag.String("text", "E3", "text for the text animation")
+	duration := flag.Duration("duration", 10*time.Second, "how long to run the animation")
+	fps := flag.Int("fps", animations.DefaultFPS, "frames per second")
+	out := flag.String("o", "", "write a .gif or .png sprite sheet instead of rendering in the terminal")
+	scale := flag.Int("scale", 16, "pixels per key in images")
+	flag.Parse()
+	if *fps <= 0 {
+		fail(fmt.Errorf("invalid fps: %d", *fps))
+	}
+	if *scale <= 0 {
+		fail(fmt.Errorf("invalid scale: %d", *scale))
+	}
+
+	a, err := animation(*name, *text)
+	if err != nil {
+		fail(err)
+	}
+
+	if *out == "" {
+		_, err = animations.NewScheduler(preview.NewTerminal(os.Stdout), *fps).Run(a, *duration)
+		if err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	rec, err := preview.Record(a, *duration, *fps)
+	if err != nil {
+		fail(err)
+	}
+	f, err := os.Create(*out)
+	if err != nil {
+		fail(err)
+	}
+	defer f.Close()
+
+	switch filepath.Ext(*out) {
+	case ".gif":
+		err = rec.WriteGIF(f, *scale)
+	case ".png":
+		err = rec.WritePNG(f, *scale)
+	default:
+		err = fmt.Errorf("unsupported image format: %s", *out)
+	}
+	if err != nil {
+		fail(err)
+	}
+}
+
+func animation(name, text string) (draw.Animation, error) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	switch name {
+	case "matrix":
+		return animations.NewMatrix(r), nil
+	case "blink":
+		return animations.NewRandomBlink(r), nil
+	case "rainbow":
+		return animations.NewInfinityRainbow(), nil
+	case "life":
+		return animations.NewLife(r), nil
+	case "pulse":
+		return animations.NewPulse(draw.RGB{R: 0x80, G: 0xFF, B: 0xFF}, draw.MaskAll, 2*time.Second), nil
+	case "text":
+		m := animations.NewMarquee(text, draw.RGB{R: 0xFF, G: 0xFF, B: 0xFF})
+		m.SetLoop(true)
+		return m, nil
+	}
+	return nil, fmt.Errorf("unknown animation: %s", name)
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(1)
+}
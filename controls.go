@@ -1,3 +1,5 @@
+//go:build tinygo
+
 package main
 
 import "trelligo/pkg/dfplayer"
@@ -15,4 +17,9 @@ type Controls interface {
 	VolumeDown() error
 	VolumeUp() error
 	LoopFolder(folder uint16) error
+	LoopFile(file uint16) error
+	PlayFolder(folder uint8, file uint8) error
+	RandomAll() error
+	SetLoop(enable bool) error
+	SetLoopAll(enable bool) error
 }
@@ -0,0 +1,74 @@
+//go:build tinygo
+
+package main
+
+import (
+	"machine"
+	"time"
+	"trelligo/pkg/debug"
+	"trelligo/pkg/seesaw"
+	"trelligo/pkg/seesaw/pwm"
+	"trelligo/pkg/seesaw/touch"
+)
+
+// touchChannel the channel of the touch pad wired up to the seesaw breakout
+const touchChannel = 0
+
+// ledPin a PWM capable pin with a status LED
+const ledPin = 4
+
+func main() {
+	machine.InitSerial()
+
+	// give some time to attach to Serial
+	time.Sleep(3 * time.Second)
+
+	// an example to dim an LED with a touch pad on a seesaw breakout
+	debug.Log("init i2c")
+	i2c := machine.I2C0
+	err := i2c.Configure(machine.I2CConfig{})
+	if err != nil {
+		panic(err)
+	}
+
+	ss := seesaw.New(seesaw.DefaultSeesawAddress, i2c)
+	err = ss.SoftReset()
+	if err != nil {
+		panic(err)
+	}
+
+	led, err := pwm.New(ss)
+	if err != nil {
+		panic(err)
+	}
+
+	pad, err := touch.New(ss, touchChannel)
+	if err != nil {
+		panic(err)
+	}
+	debug.Log("calibrating, don't touch the pad")
+	err = pad.Calibrate(10, 0)
+	if err != nil {
+		panic(err)
+	}
+
+	bright := false
+	pad.SetEdgeHandleFunc(func(e touch.Edge) error {
+		if e != touch.EdgeTouched {
+			return nil
+		}
+		bright = !bright
+		if bright {
+			return led.SetDutyCycle(ledPin, pwm.MaxDutyCycle)
+		}
+		return led.SetDutyCycle(ledPin, pwm.MaxDutyCycle/16)
+	})
+
+	for {
+		err := pad.Process()
+		if err != nil {
+			debug.Log("warn: " + err.Error())
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
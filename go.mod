@@ -1,6 +1,8 @@
 module trelligo
 
-go 1.20
+go 1.25.0
+
+require go.bug.st/serial v1.8.0
 
 require (
 	github.com/alecthomas/assert/v2 v2.3.0 // indirect
@@ -9,5 +11,6 @@ require (
 	github.com/hexops/gotextdiff v1.0.3 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/stretchr/testify v1.8.4 // indirect
+	golang.org/x/sys v0.43.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -1,3 +1,5 @@
+//go:build tinygo
+
 package main
 
 import (
@@ -6,12 +8,14 @@ import (
 	"trelligo/pkg/debug"
 	"trelligo/pkg/dfplayer"
 	"trelligo/pkg/dfplayer/uart"
+	"trelligo/pkg/draw"
 	"trelligo/pkg/draw/animations"
 	"trelligo/pkg/draw/ntdisplay"
+	"trelligo/pkg/gonybox"
 	"trelligo/pkg/hyst"
 	"trelligo/pkg/neotrellis"
-	"trelligo/pkg/player"
 	"trelligo/pkg/prng"
+	"trelligo/pkg/seesaw/keypad"
 )
 
 func main() {
@@ -47,25 +51,27 @@ func main() {
 
 	r := try(prng.NewDefault())
 
-	m := animations.NewMatrix(r)
-	err = animations.AnimateFor(display, m, 100*time.Second)
-	if err != nil {
-		panic(err)
-	}
-
-	a := animations.NewRandomBlink(r)
-	err = animations.AnimateFor(display, a, time.Second)
-	if err != nil {
-		panic(err)
-	}
-	a2 := animations.NewInfinityRainbow()
-	err = animations.AnimateFor(display, a2, time.Second)
+	boot := animations.NewSequence(
+		animations.Step{Animation: animations.NewMatrix(r), Duration: 100 * time.Second},
+		animations.Step{Animation: animations.NewRandomBlink(r), Duration: time.Second, Fade: 300 * time.Millisecond},
+		animations.Step{Animation: animations.NewInfinityRainbow(), Duration: time.Second, Fade: 300 * time.Millisecond},
+	)
+	err = runSkippable(nt, display, boot, 102*time.Second)
 	if err != nil {
 		panic(err)
 	}
 
 	debug.Log("setup player")
-	p := try(player.New(nt, dfp, h))
+	// the DFPlayer pulls BUSY low while playing
+	busyPin := machine.D6
+	busyPin.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	p := try(gonybox.NewPlayer(gonybox.Config{
+		Trellis:  nt,
+		DFPlayer: dfp,
+		Knob:     h,
+		Busy:     busyPin,
+		Rand:     r,
+	}))
 
 	for {
 		err := p.Process()
@@ -90,10 +96,52 @@ func setupNeoTrellis() (*neotrellis.Device, error) {
 
 	debug.Log("initializing neotrellis")
 	nt := try(neotrellis.New(i2c, 0))
+	// adaptive timing only goes below the datasheet delays down to the calibrated minimum
+	_, err = nt.Seesaw().Calibrate(16)
+	if err != nil {
+		debug.Log("warn: failed to calibrate read delays: " + err.Error())
+	} else {
+		nt.SetAdaptiveTiming(true)
+	}
+
+	// the seesaw pulls INT low while key events are pending, no need to poll the keypad otherwise
+	intPin := machine.D5
+	intPin.Configure(machine.PinConfig{Mode: machine.PinInputPullup})
+	nt.SetInterrupt(neotrellis.NewPinInterrupt(intPin))
 
 	return nt, nil
 }
 
+// runSkippable runs the animation until it is over or any key is pressed
+func runSkippable(nt *neotrellis.Device, display draw.Display, a draw.Animation, d time.Duration) error {
+	skip := false
+	nt.SetKeyHandleFunc(func(x, y uint8, e keypad.Edge) error {
+		skip = true
+		return nil
+	})
+	for i := uint8(0); i < 16; i++ {
+		err := nt.ConfigureKeypad(i/4, i%4, keypad.EdgeRising, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	s := animations.NewScheduler(display, animations.DefaultFPS)
+	s.SetCancelFunc(func() bool {
+		err := nt.ProcessKeyEvents()
+		if err != nil {
+			debug.Log("warn: failed to process key events: " + err.Error())
+		}
+		return skip
+	})
+
+	skipped, err := s.Run(a, d)
+	if skipped {
+		debug.Log("animation skipped")
+	}
+	return err
+}
+
 func setupDfplayer() (*dfplayer.Player, error) {
 
 	uart1 := machine.UART1
@@ -1,13 +1,7 @@
 package debug
 
-import "machine"
-
 func init() {
 }
-func Log(s string) {
-	//sudo screen /dev/ttyACM0 9600
-	machine.Serial.Write([]byte(s + "\r\n"))
-}
 
 func FmtByteToBinary(r byte) string {
 	formatted := make([]byte, 8)
@@ -0,0 +1,27 @@
+//go:build !tinygo
+
+package debug
+
+import (
+	"io"
+	"os"
+	"sync"
+)
+
+var (
+	mu     sync.Mutex
+	output io.Writer = os.Stderr
+)
+
+// SetOutput redirects the log on the host, e.g. to keep it out of a terminal UI
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	output = w
+}
+
+func Log(s string) {
+	mu.Lock()
+	defer mu.Unlock()
+	output.Write([]byte(s + "\n"))
+}
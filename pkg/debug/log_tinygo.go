@@ -0,0 +1,10 @@
+//go:build tinygo
+
+package debug
+
+import "machine"
+
+func Log(s string) {
+	//sudo screen /dev/ttyACM0 9600
+	machine.Serial.Write([]byte(s + "\r\n"))
+}
@@ -0,0 +1,20 @@
+package dfplayer
+
+// Pin is an input connected to the BUSY output of the player, e.g. a machine.Pin
+type Pin interface {
+	Get() bool
+}
+
+// Busy reads the BUSY output, which is cheaper than querying the state via UART
+type Busy struct {
+	pin Pin
+}
+
+func NewBusy(pin Pin) *Busy {
+	return &Busy{pin: pin}
+}
+
+// Playing returns whether a track is playing, BUSY is low while playing
+func (b *Busy) Playing() bool {
+	return !b.pin.Get()
+}
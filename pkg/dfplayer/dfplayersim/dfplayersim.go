@@ -0,0 +1,291 @@
+// Package dfplayersim simulates a DFPlayer Mini behind its serial protocol. It decodes the frames of the dfplayer
+// driver and models the SD card folders, playback, volume and the frames the module sends on its own well enough
+// to run the player without hardware.
+package dfplayersim
+
+import (
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+	"trelligo/pkg/dfplayer"
+)
+
+// DefaultTrackLength how long each simulated track plays
+const DefaultTrackLength = 3 * time.Minute
+
+const maxVolume = 30
+
+// Device is a simulated DFPlayer Mini, it implements dfplayer.RoundTripper and dfplayer.Receiver
+type Device struct {
+	mu  sync.Mutex
+	now func() time.Time
+	r   *rand.Rand
+
+	folders     map[uint8]uint16
+	card        bool
+	trackLength time.Duration
+
+	state     dfplayer.State
+	folder    uint8
+	file      uint16
+	startedAt time.Time
+	played    time.Duration
+	loop      bool
+	random    bool
+	volume    uint8
+	sleeping  bool
+
+	advertised []uint16
+	commands   int
+	events     []dfplayer.Frame
+}
+
+var _ dfplayer.RoundTripper = &Device{}
+var _ dfplayer.Receiver = &Device{}
+
+// New creates a simulated DFPlayer with an empty SD card, now is the clock the tracks play by
+func New(now func() time.Time) *Device {
+	return &Device{
+		now:         now,
+		r:           rand.New(rand.NewSource(1)),
+		folders:     make(map[uint8]uint16),
+		card:        true,
+		trackLength: DefaultTrackLength,
+		volume:      maxVolume,
+	}
+}
+
+// Send implements dfplayer.RoundTripper. Queries are answered with their result, commands requesting feedback
+// with an ACK.
+func (d *Device) Send(tx *dfplayer.Frame, rx *dfplayer.Frame) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.commands++
+	d.update()
+	cmd, arg := tx.Command(), tx.Argument()
+
+	if d.sleeping && cmd != dfplayer.CommandSetOutputDevice && cmd != dfplayer.CommandReset {
+		reply(rx, dfplayer.ReplyError, uint16(dfplayer.ErrorSleeping))
+		return nil
+	}
+
+	if answer, ok := d.query(cmd, arg); ok {
+		reply(rx, cmd, answer)
+		return nil
+	}
+	if err := d.command(cmd, arg); err != 0 {
+		reply(rx, dfplayer.ReplyError, uint16(err))
+		return nil
+	}
+	reply(rx, dfplayer.ReplyAck, 0)
+	return nil
+}
+
+func (d *Device) query(cmd byte, arg uint16) (uint16, bool) {
+	switch cmd {
+	case dfplayer.CommandQueryStorage:
+		if d.card {
+			return uint16(dfplayer.StorageSD), true
+		}
+		return 0, true
+	case dfplayer.CommandQueryStatus:
+		return 0x0200 | uint16(d.state), true
+	case dfplayer.CommandQueryVolume:
+		return uint16(d.volume), true
+	case dfplayer.CommandQueryCurrentSD:
+		return d.global(), true
+	case dfplayer.CommandQueryFolderFiles:
+		return d.folders[uint8(arg)], true
+	}
+	return 0, false
+}
+
+func (d *Device) command(cmd byte, arg uint16) dfplayer.DeviceError {
+	switch cmd {
+	case dfplayer.CommandPlayFolder:
+		return d.play(uint8(arg>>8), arg&0xFF)
+	case dfplayer.CommandNext:
+		return d.step(1)
+	case dfplayer.CommandPrevious:
+		return d.step(-1)
+	case dfplayer.CommandRandomAll:
+		d.random = true
+		return d.playRandom()
+	case dfplayer.CommandPause:
+		if d.state == dfplayer.StatePlaying {
+			d.played += d.now().Sub(d.startedAt)
+			d.state = dfplayer.StatePaused
+		}
+	case dfplayer.CommandStart:
+		if d.state == dfplayer.StatePaused {
+			d.startedAt = d.now()
+			d.state = dfplayer.StatePlaying
+		}
+	case dfplayer.CommandStop:
+		d.state = dfplayer.StateStopped
+		d.random = false
+	case dfplayer.CommandSetVolume:
+		if arg > maxVolume {
+			return dfplayer.ErrorSerial
+		}
+		d.volume = uint8(arg)
+	case dfplayer.CommandVolumeUp:
+		if d.volume < maxVolume {
+			d.volume++
+		}
+	case dfplayer.CommandVolumeDown:
+		if d.volume > 0 {
+			d.volume--
+		}
+	case dfplayer.CommandSetLoop:
+		// 0 starts the loop, 1 stops it
+		d.loop = arg == 0
+	case dfplayer.CommandAdvertiseFile:
+		if d.state != dfplayer.StatePlaying {
+			return dfplayer.ErrorBusy
+		}
+		d.advertised = append(d.advertised, arg)
+	case dfplayer.CommandSleep:
+		d.stop()
+		d.sleeping = true
+	case dfplayer.CommandSetOutputDevice:
+		d.sleeping = false
+	case dfplayer.CommandReset:
+		d.stop()
+		d.sleeping = false
+		d.volume = maxVolume
+		d.loop = false
+	}
+	return 0
+}
+
+func (d *Device) play(folder uint8, file uint16) dfplayer.DeviceError {
+	if !d.card {
+		return dfplayer.ErrorSDCard
+	}
+	if file == 0 || file > d.folders[folder] {
+		return dfplayer.ErrorTrackNotFound
+	}
+	d.folder, d.file = folder, file
+	d.startedAt = d.now()
+	d.played = 0
+	d.state = dfplayer.StatePlaying
+	return 0
+}
+
+// step plays the next or previous track in the global order of the card
+func (d *Device) step(delta int) dfplayer.DeviceError {
+	if d.random {
+		return d.playRandom()
+	}
+	g := int(d.global()) + delta
+	if g < 1 {
+		g = 1
+	}
+	folder, file, ok := d.fromGlobal(uint16(g))
+	if !ok {
+		return dfplayer.ErrorTrackOutRange
+	}
+	return d.play(folder, file)
+}
+
+func (d *Device) playRandom() dfplayer.DeviceError {
+	n := d.total()
+	if n == 0 {
+		return dfplayer.ErrorTrackNotFound
+	}
+	folder, file, _ := d.fromGlobal(uint16(d.r.Intn(int(n)) + 1))
+	return d.play(folder, file)
+}
+
+func (d *Device) stop() {
+	d.state = dfplayer.StateStopped
+	d.random = false
+}
+
+// update finishes the playing track once its time is up, like the module it reports this twice
+func (d *Device) update() {
+	if d.state != dfplayer.StatePlaying {
+		return
+	}
+	now := d.now()
+	if d.played+now.Sub(d.startedAt) < d.trackLength {
+		return
+	}
+	finished := d.global()
+	var f dfplayer.Frame
+	reply(&f, dfplayer.ReplyTrackFinishedSD, finished)
+	d.events = append(d.events, f, f)
+
+	switch {
+	case d.loop:
+		d.startedAt = now
+		d.played = 0
+	case d.random:
+		d.playRandom()
+	default:
+		d.state = dfplayer.StateStopped
+	}
+}
+
+// Receive implements dfplayer.Receiver
+func (d *Device) Receive(rx *dfplayer.Frame) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.update()
+	if len(d.events) == 0 {
+		return false, nil
+	}
+	*rx = d.events[0]
+	d.events = d.events[1:]
+	return true, nil
+}
+
+// global returns the number of the current track across all folders, the order the module uses
+func (d *Device) global() uint16 {
+	n := uint16(0)
+	for _, f := range d.sortedFolders() {
+		if f == d.folder {
+			return n + d.file
+		}
+		n += d.folders[f]
+	}
+	return 0
+}
+
+func (d *Device) fromGlobal(g uint16) (uint8, uint16, bool) {
+	for _, f := range d.sortedFolders() {
+		if g <= d.folders[f] {
+			return f, g, true
+		}
+		g -= d.folders[f]
+	}
+	return 0, 0, false
+}
+
+func (d *Device) total() uint16 {
+	n := uint16(0)
+	for _, files := range d.folders {
+		n += files
+	}
+	return n
+}
+
+func (d *Device) sortedFolders() []uint8 {
+	folders := make([]uint8, 0, len(d.folders))
+	for f := range d.folders {
+		folders = append(folders, f)
+	}
+	sort.Slice(folders, func(i, j int) bool { return folders[i] < folders[j] })
+	return folders
+}
+
+func reply(rx *dfplayer.Frame, cmd byte, arg uint16) {
+	*rx = dfplayer.NewFrame()
+	rx.SetCommand(cmd)
+	rx.SetArgument(arg)
+	rx.UpdateChecksum()
+}
@@ -0,0 +1,54 @@
+package dfplayersim
+
+import (
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/dfplayer"
+)
+
+func TestDevice_Playback(t *testing.T) {
+	now := time.Unix(0, 0)
+	sim := New(func() time.Time { return now })
+	sim.AddFolder(1, 3)
+	sim.AddFolder(2, 5)
+	sim.SetTrackLength(time.Minute)
+	p := dfplayer.NewPlayer(sim)
+
+	n, err := p.QueryFolderFiles(2)
+	be.NoError(t, err)
+	be.Equal(t, n, 5)
+
+	be.NoError(t, p.PlayFolder(2, 4))
+	track, err := p.QueryCurrentTrack()
+	be.NoError(t, err)
+	be.Equal(t, track, 7)
+	be.Equal(t, sim.BusyPin().Get(), false)
+
+	be.NoError(t, p.SetLoop(true))
+	be.Equal(t, sim.Looping(), true)
+	be.NoError(t, p.SetLoop(false))
+	be.Equal(t, sim.Looping(), false)
+
+	// the module reports the finished track twice
+	now = now.Add(time.Minute)
+	for i := 0; i < 2; i++ {
+		e, ok, err := p.PollEvent()
+		be.NoError(t, err)
+		be.Equal(t, ok, true)
+		be.Equal(t, e.TrackFinished(), true)
+		be.Equal(t, e.Argument, 7)
+	}
+	s, err := p.QueryState()
+	be.NoError(t, err)
+	be.Equal(t, s, dfplayer.StateStopped)
+
+	be.NoError(t, p.Sleep())
+	_, err = p.QueryState()
+	be.Equal(t, err, error(dfplayer.ErrorSleeping))
+	be.NoError(t, p.SetOutputDevice(2))
+
+	err = p.PlayFolder(3, 1)
+	be.NoError(t, err)
+	be.Equal(t, sim.State(), dfplayer.StateStopped)
+}
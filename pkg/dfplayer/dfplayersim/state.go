@@ -0,0 +1,121 @@
+package dfplayersim
+
+import (
+	"time"
+	"trelligo/pkg/dfplayer"
+)
+
+// AddFolder puts a folder with the given number of files onto the simulated SD card
+func (d *Device) AddFolder(folder uint8, files uint16) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.folders[folder] = files
+}
+
+// SetCard inserts or removes the SD card, removing it stops playback
+func (d *Device) SetCard(inserted bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.card == inserted {
+		return
+	}
+	d.card = inserted
+	cmd := byte(dfplayer.ReplyStorageInserted)
+	if !inserted {
+		cmd = dfplayer.ReplyStorageRemoved
+		d.stop()
+	}
+	var f dfplayer.Frame
+	reply(&f, cmd, uint16(dfplayer.StorageSD))
+	d.events = append(d.events, f)
+}
+
+// Card returns whether the SD card is inserted
+func (d *Device) Card() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.card
+}
+
+// SetTrackLength sets how long each track plays
+func (d *Device) SetTrackLength(l time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.trackLength = l
+}
+
+// State returns the playback state, finishing the track if its time is up
+func (d *Device) State() dfplayer.State {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.update()
+	return d.state
+}
+
+// Track returns the folder and file last played
+func (d *Device) Track() (uint8, uint16) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.folder, d.file
+}
+
+// Remaining returns how long the current track still plays, 0 if nothing plays
+func (d *Device) Remaining() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.update()
+	switch d.state {
+	case dfplayer.StatePlaying:
+		return d.trackLength - d.played - d.now().Sub(d.startedAt)
+	case dfplayer.StatePaused:
+		return d.trackLength - d.played
+	}
+	return 0
+}
+
+func (d *Device) Volume() uint8 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.volume
+}
+
+func (d *Device) Looping() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.loop
+}
+
+func (d *Device) Sleeping() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.sleeping
+}
+
+// Advertised returns the advertisements played so far
+func (d *Device) Advertised() []uint16 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]uint16(nil), d.advertised...)
+}
+
+// Commands returns the number of frames received
+func (d *Device) Commands() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.commands
+}
+
+// BusyPin simulates the BUSY pin, it is low while playing
+type BusyPin struct {
+	d *Device
+}
+
+var _ dfplayer.Pin = BusyPin{}
+
+func (d *Device) BusyPin() BusyPin {
+	return BusyPin{d: d}
+}
+
+func (p BusyPin) Get() bool {
+	return p.d.State() != dfplayer.StatePlaying
+}
@@ -0,0 +1,43 @@
+package dfplayer
+
+// Receiver is implemented by RoundTrippers that can receive frames the player sends on its own. Receive must not
+// block, it returns false if no frame is pending.
+type Receiver interface {
+	Receive(rx *Frame) (bool, error)
+}
+
+// Event is a frame the player sent on its own, e.g. when a track finished
+type Event struct {
+	Command  byte
+	Argument uint16
+}
+
+// TrackFinished returns whether a track finished playing, Argument is the global number of the track
+func (e Event) TrackFinished() bool {
+	switch e.Command {
+	case ReplyTrackFinishedUSB, ReplyTrackFinishedSD, ReplyTrackFinishedFlash:
+		return true
+	}
+	return false
+}
+
+// StorageChanged returns whether a storage device was inserted or removed
+func (e Event) StorageChanged() bool {
+	return e.Command == ReplyStorageInserted || e.Command == ReplyStorageRemoved
+}
+
+// PollEvent returns the next pending event, false if there is none or the RoundTripper isn't a Receiver.
+// RoundTrippers that don't queue them may drop frames received before sending a command, so poll before sending
+// commands.
+func (d *Player) PollEvent() (Event, bool, error) {
+	r, ok := d.roundTripper.(Receiver)
+	if !ok {
+		return Event{}, false, nil
+	}
+	var f Frame
+	ok, err := r.Receive(&f)
+	if err != nil || !ok {
+		return Event{}, false, err
+	}
+	return Event{Command: f.Command(), Argument: f.Argument()}, true, nil
+}
@@ -43,6 +43,24 @@ func (f *Frame) SetArgument(arg uint16) {
 	f[positionQueryLowByte] = byte(arg)
 }
 
+func (f *Frame) Command() byte {
+	return f[positionCommand]
+}
+
+func (f *Frame) Argument() uint16 {
+	return uint16(f[positionQueryHighByte])<<8 | uint16(f[positionQueryLowByte])
+}
+
+// Valid returns whether start code, end code and checksum are in place, e.g. to sync to frames on the wire
+func (f *Frame) Valid() bool {
+	if f[positionStart] != 0x7E || f[9] != 0xEF {
+		return false
+	}
+	c := *f
+	c.UpdateChecksum()
+	return c == *f
+}
+
 func (f *Frame) UpdateChecksum() {
 	/*
 		// Reference implementation:
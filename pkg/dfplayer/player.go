@@ -2,6 +2,7 @@ package dfplayer
 
 import (
 	"errors"
+	"strconv"
 )
 
 var ErrVolumeOutOfRange = errors.New("volume out of range")
@@ -34,6 +35,68 @@ const (
 	CommandSetDAC                 = 0x1A
 )
 
+// Queries, the player answers with a frame of the same command
+const (
+	CommandQueryStorage     = 0x3F
+	CommandQueryStatus      = 0x42
+	CommandQueryVolume      = 0x43
+	CommandQueryCurrentSD   = 0x4C
+	CommandQueryFolderFiles = 0x4E
+)
+
+// Replies the player sends on its own
+const (
+	ReplyStorageInserted    = 0x3A
+	ReplyStorageRemoved     = 0x3B
+	ReplyTrackFinishedUSB   = 0x3C
+	ReplyTrackFinishedSD    = 0x3D
+	ReplyTrackFinishedFlash = 0x3E
+	ReplyError              = 0x40
+	ReplyAck                = 0x41
+)
+
+// Storage is a bitmask of the storage devices online
+type Storage uint16
+
+const (
+	StorageUSB   Storage = 0x01
+	StorageSD    Storage = 0x02
+	StoragePC    Storage = 0x04
+	StorageFlash Storage = 0x08
+)
+
+// Has returns whether all devices of s are online
+func (st Storage) Has(s Storage) bool {
+	return st&s == s
+}
+
+// State is the playback state
+type State uint8
+
+const (
+	StateStopped State = 0
+	StatePlaying State = 1
+	StatePaused  State = 2
+)
+
+// DeviceError is an error code the player replied with
+type DeviceError uint16
+
+const (
+	ErrorBusy          DeviceError = 0x01
+	ErrorSleeping      DeviceError = 0x02
+	ErrorSerial        DeviceError = 0x03
+	ErrorChecksum      DeviceError = 0x04
+	ErrorTrackOutRange DeviceError = 0x05
+	ErrorTrackNotFound DeviceError = 0x06
+	ErrorInsertion     DeviceError = 0x07
+	ErrorSDCard        DeviceError = 0x08
+)
+
+func (e DeviceError) Error() string {
+	return "device error 0x" + strconv.FormatUint(uint64(e), 16)
+}
+
 const (
 	positionStart            = 0
 	positionVersion          = 1
@@ -184,12 +247,13 @@ func (d *Player) RandomAll() error {
 	return d.sendCommand(CommandRandomAll)
 }
 
+// SetLoop repeats the current track, the module starts the loop with 0 and stops it with 1
 func (d *Player) SetLoop(enable bool) error {
-	var isEnabled uint16
-	if enable {
-		isEnabled = 1
+	var isDisabled uint16
+	if !enable {
+		isDisabled = 1
 	}
-	return d.sendCommandWithArg(CommandSetLoop, isEnabled)
+	return d.sendCommandWithArg(CommandSetLoop, isDisabled)
 }
 
 func (d *Player) SetDAC(enable bool) error {
@@ -200,6 +264,59 @@ func (d *Player) SetDAC(enable bool) error {
 	return d.sendCommandWithArg(CommandSetDAC, isEnabled)
 }
 
+// QueryStorage returns the storage devices online, e.g. to check for a missing SD card
+func (d *Player) QueryStorage() (Storage, error) {
+	arg, err := d.query(CommandQueryStorage)
+	return Storage(arg), err
+}
+
+// QueryState returns whether the player is playing, paused or stopped
+func (d *Player) QueryState() (State, error) {
+	arg, err := d.query(CommandQueryStatus)
+	// the high byte is the storage device
+	return State(arg), err
+}
+
+// QueryCurrentTrack returns the number of the track playing from the SD card, counted across all folders
+func (d *Player) QueryCurrentTrack() (uint16, error) {
+	return d.query(CommandQueryCurrentSD)
+}
+
+// QueryFolderFiles returns the number of files in a folder, e.g. SD:/05
+func (d *Player) QueryFolderFiles(folder uint8) (uint16, error) {
+	return d.queryWithArg(CommandQueryFolderFiles, uint16(folder))
+}
+
+// QueryVolume returns the current volume in the range [0,30]
+func (d *Player) QueryVolume() (uint8, error) {
+	arg, err := d.query(CommandQueryVolume)
+	return uint8(arg), err
+}
+
+func (d *Player) query(cmd byte) (uint16, error) {
+	return d.queryWithArg(cmd, 0)
+}
+
+// queryWithArg sends a query without requesting feedback, the single frame the player replies with is the answer
+func (d *Player) queryWithArg(cmd byte, arg uint16) (uint16, error) {
+	d.txBuffer.SetFeedback(false)
+	defer d.txBuffer.SetFeedback(true)
+
+	err := d.sendCommandWithArg(cmd, arg)
+	if err != nil {
+		return 0, err
+	}
+
+	switch d.rxBuffer.Command() {
+	case cmd:
+		return d.rxBuffer.Argument(), nil
+	case ReplyError:
+		return 0, DeviceError(d.rxBuffer.Argument())
+	}
+	return 0, errors.New("unexpected reply 0x" + strconv.FormatUint(uint64(d.rxBuffer.Command()), 16) +
+		" to query 0x" + strconv.FormatUint(uint64(cmd), 16))
+}
+
 func (d *Player) sendCommand(cmd byte) error {
 	d.txBuffer.SetCommand(cmd)
 	d.txBuffer.UpdateChecksum()
@@ -0,0 +1,128 @@
+package dfplayer
+
+import (
+	"testing"
+	"trelligo/pkg/be"
+)
+
+type replyRoundTripper struct {
+	sent  Frame
+	reply Frame
+}
+
+func (r *replyRoundTripper) Send(tx *Frame, rx *Frame) error {
+	r.sent = *tx
+	*rx = r.reply
+	return nil
+}
+
+func reply(cmd byte, arg uint16) Frame {
+	f := NewFrame()
+	f.SetCommand(cmd)
+	f.SetArgument(arg)
+	f.UpdateChecksum()
+	return f
+}
+
+func TestPlayer_QueryStorage(t *testing.T) {
+	rt := &replyRoundTripper{reply: reply(CommandQueryStorage, uint16(StorageSD))}
+	p := NewPlayer(rt)
+
+	s, err := p.QueryStorage()
+	be.NoError(t, err)
+	be.Equal(t, s.Has(StorageSD), true)
+	be.Equal(t, s.Has(StorageUSB), false)
+	be.Equal(t, rt.sent.Command(), CommandQueryStorage)
+	be.Equal(t, rt.sent[positionFeedback], 0)
+
+	// feedback is back on for regular commands
+	be.NoError(t, p.Stop())
+	be.Equal(t, rt.sent[positionFeedback], 1)
+}
+
+func TestPlayer_QueryState(t *testing.T) {
+	rt := &replyRoundTripper{reply: reply(CommandQueryStatus, 0x0201)}
+	p := NewPlayer(rt)
+
+	s, err := p.QueryState()
+	be.NoError(t, err)
+	be.Equal(t, s, StatePlaying)
+
+	rt.reply = reply(CommandQueryFolderFiles, 12)
+	n, err := p.QueryFolderFiles(5)
+	be.NoError(t, err)
+	be.Equal(t, n, 12)
+	be.Equal(t, rt.sent.Argument(), 5)
+}
+
+func TestPlayer_QueryError(t *testing.T) {
+	rt := &replyRoundTripper{reply: reply(ReplyError, uint16(ErrorSDCard))}
+	p := NewPlayer(rt)
+
+	_, err := p.QueryVolume()
+	be.Equal(t, err, error(ErrorSDCard))
+
+	rt.reply = reply(ReplyAck, 0)
+	_, err = p.QueryVolume()
+	be.AnError(t, err)
+}
+
+func TestPlayer_SetLoop(t *testing.T) {
+	rt := &replyRoundTripper{reply: reply(ReplyAck, 0)}
+	p := NewPlayer(rt)
+
+	// the module loops on 0 and stops on 1
+	be.NoError(t, p.SetLoop(true))
+	want := NewFrame()
+	want.SetCommand(CommandSetLoop)
+	want.SetFeedback(true)
+	want.SetArgument(0)
+	want.UpdateChecksum()
+	be.Equal(t, rt.sent, want)
+
+	be.NoError(t, p.SetLoop(false))
+	want.SetArgument(1)
+	want.UpdateChecksum()
+	be.Equal(t, rt.sent, want)
+}
+
+type eventRoundTripper struct {
+	replyRoundTripper
+	events []Frame
+}
+
+func (r *eventRoundTripper) Receive(rx *Frame) (bool, error) {
+	if len(r.events) == 0 {
+		return false, nil
+	}
+	*rx = r.events[0]
+	r.events = r.events[1:]
+	return true, nil
+}
+
+func TestPlayer_PollEvent(t *testing.T) {
+	rt := &eventRoundTripper{events: []Frame{reply(ReplyTrackFinishedSD, 7)}}
+	p := NewPlayer(rt)
+
+	e, ok, err := p.PollEvent()
+	be.NoError(t, err)
+	be.Equal(t, ok, true)
+	be.Equal(t, e.TrackFinished(), true)
+	be.Equal(t, e.Argument, 7)
+
+	_, ok, err = p.PollEvent()
+	be.NoError(t, err)
+	be.Equal(t, ok, false)
+
+	// without a Receiver there are no events
+	_, ok, err = NewPlayer(&replyRoundTripper{}).PollEvent()
+	be.NoError(t, err)
+	be.Equal(t, ok, false)
+}
+
+func TestFrame_Valid(t *testing.T) {
+	f := reply(ReplyTrackFinishedSD, 3)
+	be.Equal(t, f.Valid(), true)
+	f[positionQueryLowByte] = 4
+	be.Equal(t, f.Valid(), false)
+}
@@ -1,3 +1,5 @@
+//go:build tinygo
+
 package uart
 
 import (
@@ -8,29 +10,71 @@ import (
 )
 
 var _ = dfplayer.RoundTripper(&RoundTripper{})
+var _ = dfplayer.Receiver(&RoundTripper{})
+var _ = dfplayer.Pin(machine.Pin(0))
+
+// maxPending is how many frames the player sent on its own are kept until Receive picks them up
+const maxPending = 4
 
 type RoundTripper struct {
 	port     *machine.UART
 	rxBuffer []byte
+	pending  []dfplayer.Frame
 }
 
 func NewRoundTripper(uart *machine.UART) *RoundTripper {
 	return &RoundTripper{
 		port:     uart,
 		rxBuffer: make([]byte, 10),
+		pending:  make([]dfplayer.Frame, 0, maxPending),
 	}
 }
 
 func (u *RoundTripper) Send(tx *dfplayer.Frame, rx *dfplayer.Frame) error {
-	// clear the input buffer as we don't care about things already queued
-	u.port.Buffer.Clear()
+	// keep frames already queued for Receive, a finished track must not get lost because a command was sent
+	var f dfplayer.Frame
+	for {
+		ok, err := u.readFrame(&f)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		if unsolicited(&f) {
+			u.keep(&f)
+		}
+	}
 
 	_, err := u.port.Write(tx[:])
 	if err != nil {
 		return err
 	}
 	deadline := time.Now().Add(time.Millisecond * 200)
-	return u.readToDeadline(rx, deadline)
+	for {
+		if err := u.readToDeadline(rx, deadline); err != nil {
+			return err
+		}
+		if !unsolicited(rx) {
+			return nil
+		}
+		// the player finished a track or saw a card while we waited for the reply
+		u.keep(rx)
+	}
+}
+
+func unsolicited(f *dfplayer.Frame) bool {
+	e := dfplayer.Event{Command: f.Command()}
+	return e.TrackFinished() || e.StorageChanged()
+}
+
+// keep queues f for Receive, dropping the oldest frame when the queue is full
+func (u *RoundTripper) keep(f *dfplayer.Frame) {
+	if len(u.pending) == maxPending {
+		copy(u.pending, u.pending[1:])
+		u.pending = u.pending[:maxPending-1]
+	}
+	u.pending = append(u.pending, *f)
 }
 
 func (u *RoundTripper) readToDeadline(rx *dfplayer.Frame, deadline time.Time) error {
@@ -55,3 +99,41 @@ func (u *RoundTripper) readToDeadline(rx *dfplayer.Frame, deadline time.Time) er
 	*rx = *arr
 	return nil
 }
+
+// Receive reads a frame the player sent on its own, e.g. when a track finished. It doesn't block, bytes until the
+// next start code are skipped. Frames that arrived while sending a command are returned first.
+func (u *RoundTripper) Receive(rx *dfplayer.Frame) (bool, error) {
+	if len(u.pending) > 0 {
+		*rx = u.pending[0]
+		copy(u.pending, u.pending[1:])
+		u.pending = u.pending[:len(u.pending)-1]
+		return true, nil
+	}
+	return u.readFrame(rx)
+}
+
+// readFrame reads the next valid frame if a whole one is buffered
+func (u *RoundTripper) readFrame(rx *dfplayer.Frame) (bool, error) {
+	var f dfplayer.Frame
+	for u.port.Buffered() >= len(f) {
+		_, err := u.port.Read(f[:1])
+		if err != nil {
+			return false, err
+		}
+		if f[0] != 0x7E {
+			continue
+		}
+		for n := 1; n < len(f); {
+			m, err := u.port.Read(f[n:])
+			if err != nil {
+				return false, err
+			}
+			n += m
+		}
+		if f.Valid() {
+			*rx = f
+			return true, nil
+		}
+	}
+	return false, nil
+}
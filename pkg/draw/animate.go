@@ -18,7 +18,8 @@ type Display interface {
 	WriteBuffer(b *Buffer4x4) error
 }
 
+// Animation advances its state in Update, which reports whether the next Draw shows a different frame
 type Animation interface {
-	Update(now time.Time)
+	Update(now time.Time) bool
 	Draw(d Display) error
 }
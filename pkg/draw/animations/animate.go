@@ -5,16 +5,98 @@ import (
 	"trelligo/pkg/draw"
 )
 
+// DefaultFPS is fast enough for smooth fades while leaving the I2C bus idle most of the time
+const DefaultFPS = 30
+
+// AnimateFor runs the animation at DefaultFPS for the given duration
 func AnimateFor(display draw.Display, a draw.Animation, duration time.Duration) error {
-	start := time.Now()
-	now := start
-	for now.Sub(start) < duration {
-		now = time.Now()
-		a.Update(now)
-		err := a.Draw(display)
-		if err != nil {
-			return err
+	_, err := NewScheduler(display, DefaultFPS).Run(a, duration)
+	return err
+}
+
+// Stats are the frame statistics of a Scheduler
+type Stats struct {
+	// Frames is the number of frames, Drawn the ones that changed and were sent to the display
+	Frames int
+	Drawn  int
+	// Dropped counts frames skipped because updating and drawing took longer than the frame time
+	Dropped int
+	// FrameTime is the total and MaxFrameTime the longest time spent updating and drawing a frame
+	FrameTime    time.Duration
+	MaxFrameTime time.Duration
+}
+
+// Scheduler runs animations at a fixed frame rate and only draws frames that changed
+type Scheduler struct {
+	display  draw.Display
+	interval time.Duration
+	cancel   func() bool
+	stats    Stats
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func NewScheduler(display draw.Display, fps int) *Scheduler {
+	if fps <= 0 {
+		fps = DefaultFPS
+	}
+	return &Scheduler{
+		display:  display,
+		interval: time.Second / time.Duration(fps),
+		now:      time.Now,
+		sleep:    time.Sleep,
+	}
+}
+
+// SetCancelFunc sets a function that is polled once per frame, the animation stops as soon as it returns true, e.g.
+// when a key was pressed
+func (s *Scheduler) SetCancelFunc(cancel func() bool) {
+	s.cancel = cancel
+}
+
+// Stats returns the frame statistics of all runs so far
+func (s *Scheduler) Stats() Stats {
+	return s.stats
+}
+
+// Run updates the animation once per frame for the given duration and draws it whenever it changed. Returns whether
+// the run was cancelled.
+func (s *Scheduler) Run(a draw.Animation, duration time.Duration) (bool, error) {
+	start := s.now()
+	next := start
+	for {
+		frameStart := s.now()
+		if frameStart.Sub(start) >= duration {
+			return false, nil
+		}
+		if s.cancel != nil && s.cancel() {
+			return true, nil
+		}
+
+		s.stats.Frames++
+		if a.Update(frameStart) {
+			s.stats.Drawn++
+			err := a.Draw(s.display)
+			if err != nil {
+				return false, err
+			}
+		}
+
+		end := s.now()
+		frameTime := end.Sub(frameStart)
+		s.stats.FrameTime += frameTime
+		if frameTime > s.stats.MaxFrameTime {
+			s.stats.MaxFrameTime = frameTime
+		}
+
+		next = next.Add(s.interval)
+		if late := end.Sub(next); late > 0 {
+			// don't try to catch up, skip to the next frame that is still ahead
+			dropped := int(late/s.interval) + 1
+			s.stats.Dropped += dropped
+			next = next.Add(time.Duration(dropped) * s.interval)
 		}
+		s.sleep(next.Sub(end))
 	}
-	return nil
 }
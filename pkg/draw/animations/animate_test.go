@@ -0,0 +1,95 @@
+package animations
+
+import (
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/draw"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	if d > 0 {
+		c.now = c.now.Add(d)
+	}
+}
+
+type slowDisplay struct {
+	clock  *fakeClock
+	delay  time.Duration
+	writes int
+}
+
+func (d *slowDisplay) WriteBuffer(*draw.Buffer4x4) error {
+	d.writes++
+	d.clock.Sleep(d.delay)
+	return nil
+}
+
+func newTestScheduler(d *slowDisplay, fps int) *Scheduler {
+	s := NewScheduler(d, fps)
+	s.now = d.clock.Now
+	s.sleep = d.clock.Sleep
+	return s
+}
+
+func TestScheduler_Run(t *testing.T) {
+	d := &slowDisplay{clock: &fakeClock{now: time.Unix(0, 0)}}
+	s := newTestScheduler(d, 10)
+
+	cancelled, err := s.Run(NewInfinityRainbow(), time.Second)
+	be.NoError(t, err)
+	be.Equal(t, cancelled, false)
+
+	st := s.Stats()
+	be.Equal(t, st.Frames, 10)
+	be.Equal(t, st.Drawn, 10)
+	be.Equal(t, st.Dropped, 0)
+	be.Equal(t, d.writes, 10)
+}
+
+func TestScheduler_OnlyDrawsChanges(t *testing.T) {
+	d := &slowDisplay{clock: &fakeClock{now: time.Unix(0, 0)}}
+	s := newTestScheduler(d, 50)
+
+	_, err := s.Run(newFill(red), time.Second)
+	be.NoError(t, err)
+	be.Equal(t, s.Stats().Frames, 50)
+	be.Equal(t, d.writes, 1)
+}
+
+func TestScheduler_Dropped(t *testing.T) {
+	d := &slowDisplay{clock: &fakeClock{now: time.Unix(0, 0)}, delay: 250 * time.Millisecond}
+	s := newTestScheduler(d, 10)
+
+	_, err := s.Run(NewInfinityRainbow(), time.Second)
+	be.NoError(t, err)
+
+	st := s.Stats()
+	be.Equal(t, st.Frames, 4)
+	be.Equal(t, st.Dropped, 8)
+	be.Equal(t, st.MaxFrameTime, 250*time.Millisecond)
+}
+
+func TestScheduler_Cancel(t *testing.T) {
+	d := &slowDisplay{clock: &fakeClock{now: time.Unix(0, 0)}}
+	s := newTestScheduler(d, 10)
+
+	frames := 0
+	s.SetCancelFunc(func() bool {
+		frames++
+		return frames > 3
+	})
+
+	cancelled, err := s.Run(NewInfinityRainbow(), time.Minute)
+	be.NoError(t, err)
+	be.Equal(t, cancelled, true)
+	be.Equal(t, s.Stats().Frames, 3)
+}
@@ -0,0 +1,217 @@
+package animations
+
+import (
+	"time"
+	"trelligo/pkg/draw"
+)
+
+// Step is an animation within a sequence. Fade is the time to crossfade from the previous step, it is part of the
+// duration.
+type Step struct {
+	Animation draw.Animation
+	Duration  time.Duration
+	Fade      time.Duration
+}
+
+type Sequence struct {
+	steps   []Step
+	loop    bool
+	current int
+	started time.Time
+	alpha   uint8
+	done    bool
+
+	prev draw.Capture
+	cur  draw.Capture
+}
+
+// NewSequence plays the steps one after another, the last step keeps running once its duration is over
+func NewSequence(steps ...Step) *Sequence {
+	return &Sequence{steps: steps, alpha: 0xFF}
+}
+
+// NewLoop plays the steps one after another and starts over after the last one
+func NewLoop(steps ...Step) *Sequence {
+	s := NewSequence(steps...)
+	s.loop = true
+	return s
+}
+
+// Done returns whether the last step of a non-looping sequence is over
+func (s *Sequence) Done() bool {
+	return s.done
+}
+
+func (s *Sequence) Update(now time.Time) bool {
+	if len(s.steps) == 0 {
+		return false
+	}
+	changed := false
+	if s.started.IsZero() {
+		s.started = now
+		changed = true
+	}
+
+	for now.Sub(s.started) >= s.steps[s.current].Duration && !s.done {
+		next := s.current + 1
+		if next == len(s.steps) {
+			if !s.loop {
+				s.done = true
+				break
+			}
+			next = 0
+		}
+		s.started = s.started.Add(s.steps[s.current].Duration)
+		s.current = next
+		changed = true
+		if s.steps[s.current].Duration <= 0 {
+			// avoid spinning on empty steps
+			s.started = now
+			break
+		}
+	}
+
+	step := s.steps[s.current]
+	changed = step.Animation.Update(now) || changed
+
+	alpha := uint8(0xFF)
+	elapsed := now.Sub(s.started)
+	if step.Fade > 0 && elapsed < step.Fade && s.hasPrevious() {
+		changed = s.steps[s.previous()].Animation.Update(now) || changed
+		alpha = uint8(elapsed * 0xFF / step.Fade)
+	}
+	if alpha != s.alpha {
+		s.alpha = alpha
+		changed = true
+	}
+	return changed
+}
+
+func (s *Sequence) Draw(d draw.Display) error {
+	if len(s.steps) == 0 {
+		return nil
+	}
+	step := s.steps[s.current]
+	if s.alpha == 0xFF {
+		return step.Animation.Draw(d)
+	}
+
+	err := s.steps[s.previous()].Animation.Draw(&s.prev)
+	if err != nil {
+		return err
+	}
+	err = step.Animation.Draw(&s.cur)
+	if err != nil {
+		return err
+	}
+	draw.BlendBuffer(&s.prev.Buf, &s.cur.Buf, s.alpha, draw.MaskAll)
+	return d.WriteBuffer(&s.prev.Buf)
+}
+
+func (s *Sequence) hasPrevious() bool {
+	return s.current > 0 || s.loop
+}
+
+func (s *Sequence) previous() int {
+	if s.current == 0 {
+		return len(s.steps) - 1
+	}
+	return s.current - 1
+}
+
+// NewCrossfade fades from one animation to the other within the given duration, starting with the first update
+func NewCrossfade(from, to draw.Animation, d time.Duration) *Sequence {
+	return NewSequence(
+		Step{Animation: from},
+		Step{Animation: to, Duration: d, Fade: d},
+	)
+}
+
+// Layer is an animation drawn over the layers below. Only keys within the mask are drawn, a zero mask selects all
+// keys.
+type Layer struct {
+	Animation draw.Animation
+	Alpha     uint8
+	Mask      draw.Mask
+}
+
+type Layers struct {
+	base   draw.Animation
+	layers []Layer
+	dirty  bool
+
+	frame draw.Capture
+	over  draw.Capture
+}
+
+// NewLayers draws the layers over the base animation, from first to last
+func NewLayers(base draw.Animation, layers ...Layer) *Layers {
+	return &Layers{base: base, layers: layers, dirty: true}
+}
+
+// SetBase replaces the base animation
+func (l *Layers) SetBase(base draw.Animation) {
+	l.base = base
+	l.dirty = true
+}
+
+// SetLayer replaces the layer at index i
+func (l *Layers) SetLayer(i int, layer Layer) {
+	l.layers[i] = layer
+	l.dirty = true
+}
+
+func (l *Layers) Update(now time.Time) bool {
+	changed := l.base.Update(now) || l.dirty
+	l.dirty = false
+	for _, layer := range l.layers {
+		if layer.Animation != nil {
+			changed = layer.Animation.Update(now) || changed
+		}
+	}
+	return changed
+}
+
+func (l *Layers) Draw(d draw.Display) error {
+	err := l.base.Draw(&l.frame)
+	if err != nil {
+		return err
+	}
+
+	for _, layer := range l.layers {
+		if layer.Animation == nil || layer.Alpha == 0 {
+			continue
+		}
+		err = layer.Animation.Draw(&l.over)
+		if err != nil {
+			return err
+		}
+		mask := layer.Mask
+		if mask == 0 {
+			mask = draw.MaskAll
+		}
+		draw.BlendBuffer(&l.frame.Buf, &l.over.Buf, layer.Alpha, mask)
+	}
+	return d.WriteBuffer(&l.frame.Buf)
+}
+
+type static struct {
+	buf   *draw.Buffer4x4
+	last  draw.Buffer4x4
+	drawn bool
+}
+
+// NewStatic draws the buffer as is, changes to the buffer show up with the next draw
+func NewStatic(buf *draw.Buffer4x4) draw.Animation {
+	return &static{buf: buf}
+}
+
+func (s *static) Update(time.Time) bool {
+	return !s.drawn || *s.buf != s.last
+}
+
+func (s *static) Draw(d draw.Display) error {
+	s.last = *s.buf
+	s.drawn = true
+	return d.WriteBuffer(s.buf)
+}
@@ -0,0 +1,116 @@
+package animations
+
+import (
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/draw"
+)
+
+type fill struct {
+	buf   draw.Buffer4x4
+	drawn bool
+}
+
+func newFill(c draw.RGB) *fill {
+	f := &fill{}
+	for i := range f.buf {
+		f.buf[i] = c
+	}
+	return f
+}
+
+func (f *fill) Update(time.Time) bool {
+	return !f.drawn
+}
+
+func (f *fill) Draw(d draw.Display) error {
+	f.drawn = true
+	return d.WriteBuffer(&f.buf)
+}
+
+var (
+	red  = draw.RGB{R: 0xFF}
+	blue = draw.RGB{B: 0xFF}
+)
+
+func TestSequence(t *testing.T) {
+	start := time.Unix(0, 0)
+	s := NewLoop(
+		Step{Animation: newFill(red), Duration: time.Second},
+		Step{Animation: newFill(blue), Duration: time.Second},
+	)
+
+	var c draw.Capture
+	for _, tc := range []struct {
+		at       time.Duration
+		expected draw.RGB
+	}{
+		{0, red},
+		{999 * time.Millisecond, red},
+		{time.Second, blue},
+		{2500 * time.Millisecond, red},
+		{3 * time.Second, blue},
+	} {
+		s.Update(start.Add(tc.at))
+		be.NoError(t, s.Draw(&c))
+		be.Equal(t, c.Buf[5], tc.expected)
+	}
+	be.Equal(t, s.Done(), false)
+}
+
+func TestSequence_Done(t *testing.T) {
+	start := time.Unix(0, 0)
+	s := NewSequence(
+		Step{Animation: newFill(red), Duration: time.Second},
+		Step{Animation: newFill(blue), Duration: time.Second},
+	)
+	s.Update(start)
+	s.Update(start.Add(5 * time.Second))
+	be.Equal(t, s.Done(), true)
+
+	var c draw.Capture
+	be.NoError(t, s.Draw(&c))
+	be.Equal(t, c.Buf[0], blue)
+}
+
+func TestCrossfade(t *testing.T) {
+	start := time.Unix(0, 0)
+	f := NewCrossfade(newFill(red), newFill(blue), time.Second)
+
+	var c draw.Capture
+	f.Update(start.Add(500 * time.Millisecond))
+	f.Update(start.Add(time.Second))
+	be.NoError(t, f.Draw(&c))
+	be.Equal(t, c.Buf[0], draw.RGB{R: 0x80, B: 0x7F})
+
+	f.Update(start.Add(2 * time.Second))
+	be.NoError(t, f.Draw(&c))
+	be.Equal(t, c.Buf[0], blue)
+}
+
+func TestLayers(t *testing.T) {
+	mask := draw.MaskOf(1, 2)
+	l := NewLayers(newFill(red), Layer{Animation: newFill(blue), Alpha: 0xFF, Mask: mask})
+
+	var c draw.Capture
+	l.Update(time.Unix(0, 0))
+	be.NoError(t, l.Draw(&c))
+	be.Equal(t, c.Buf[0], red)
+	be.Equal(t, c.Buf[4*1+2], blue)
+}
+
+func TestPulse(t *testing.T) {
+	start := time.Unix(0, 0)
+	p := NewPulse(red, draw.MaskOf(0, 0), time.Second)
+
+	var c draw.Capture
+	p.Update(start)
+	be.NoError(t, p.Draw(&c))
+	be.Equal(t, c.Buf[0], draw.RGB{})
+
+	p.Update(start.Add(500 * time.Millisecond))
+	be.NoError(t, p.Draw(&c))
+	be.Equal(t, c.Buf[0], red)
+	be.Equal(t, c.Buf[1], draw.RGB{})
+}
@@ -0,0 +1,46 @@
+package animations
+
+import (
+	"testing"
+	"time"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/draw/drawtest"
+)
+
+func TestRandomBlink_Draw(t *testing.T) {
+	drawtest.Snapshot(t, NewRandomBlink(drawtest.NewRand()), 10, 100*time.Millisecond)
+}
+
+func TestInfinityRainbow_Draw(t *testing.T) {
+	drawtest.Snapshot(t, NewInfinityRainbow(), 20, 50*time.Millisecond)
+}
+
+func TestPulse_Draw(t *testing.T) {
+	drawtest.Snapshot(t, NewPulse(draw.RGB{R: 0x80, G: 0xFF, B: 0xFF}, draw.MaskOf(1, 2)|draw.MaskRow(0), time.Second), 10, 100*time.Millisecond)
+}
+
+func TestMarquee_Draw(t *testing.T) {
+	drawtest.Snapshot(t, NewMarquee("E3", draw.RGB{R: 0xFF}), 12, DefaultMarqueeSpeed)
+}
+
+func TestStatic_Draw(t *testing.T) {
+	var buf draw.Buffer4x4
+	buf.Set(1, 2, draw.RGB{G: 0xFF})
+	drawtest.Snapshot(t, NewStatic(&buf), 2, time.Second)
+}
+
+func TestSequence_Draw(t *testing.T) {
+	s := NewSequence(
+		Step{Animation: NewInfinityRainbow(), Duration: 500 * time.Millisecond},
+		Step{Animation: NewMarquee("1", draw.RGB{B: 0xFF}), Duration: time.Second, Fade: 300 * time.Millisecond},
+	)
+	drawtest.Snapshot(t, s, 15, 100*time.Millisecond)
+}
+
+func TestLayers_Draw(t *testing.T) {
+	l := NewLayers(
+		NewInfinityRainbow(),
+		Layer{Animation: NewPulse(draw.RGB{R: 0xFF, G: 0xFF, B: 0xFF}, draw.MaskAll, time.Second), Alpha: 0x80, Mask: draw.MaskColumn(3)},
+	)
+	drawtest.Snapshot(t, l, 10, 100*time.Millisecond)
+}
@@ -0,0 +1,232 @@
+package animations
+
+import (
+	"time"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/shims/rand"
+)
+
+const (
+	lifeDefaultSpeed = 400 * time.Millisecond
+	// lifeDensity is the chance in percent of a cell being alive after seeding
+	lifeDensity = 40
+	// lifeStallAfter is the number of generations a board may repeat itself before it is reseeded
+	lifeStallAfter = 6
+	// lifeHistory is the longest oscillator period detected as stall
+	lifeHistory = 4
+)
+
+// Life is Conway's Game of Life on a grid that wraps around at the edges. Cells are colored by their age and the grid
+// is reseeded once it died out or got stuck repeating itself.
+type Life struct {
+	width, height uint8
+	cells         []uint8
+	next          []uint8
+	rnd           *rand.Rand
+	speed         time.Duration
+
+	history [lifeHistory]uint64
+	stalled int
+
+	lastUpdate time.Time
+	dirty      bool
+	buf        draw.Buffer4x4
+}
+
+// NewLife creates a randomly seeded 4x4 grid
+func NewLife(r *rand.Rand) *Life {
+	return NewLifeSize(r, 4, 4)
+}
+
+// NewLifeSize creates a randomly seeded grid, e.g. to span several boards via DrawFrame
+func NewLifeSize(r *rand.Rand, width, height uint8) *Life {
+	n := int(width) * int(height)
+	l := &Life{
+		width:  width,
+		height: height,
+		cells:  make([]uint8, n),
+		next:   make([]uint8, n),
+		rnd:    r,
+		speed:  lifeDefaultSpeed,
+	}
+	l.Reseed()
+	return l
+}
+
+// SetSpeed sets the time per generation
+func (l *Life) SetSpeed(d time.Duration) {
+	l.speed = d
+}
+
+// Reseed fills the grid with random cells
+func (l *Life) Reseed() {
+	for i := range l.cells {
+		l.cells[i] = 0
+		if l.rnd.Intn(100) < lifeDensity {
+			l.cells[i] = 1
+		}
+	}
+	l.stalled = 0
+	l.history = [lifeHistory]uint64{}
+	l.dirty = true
+}
+
+// Seed brings the cell at x/y to life, e.g. when its key is pressed. The next generation is delayed to show it.
+func (l *Life) Seed(x, y uint8, now time.Time) {
+	if x >= l.width || y >= l.height {
+		return
+	}
+	i := l.index(x, y)
+	if l.cells[i] == 0 {
+		l.cells[i] = 1
+	}
+	l.stalled = 0
+	l.lastUpdate = now
+	l.dirty = true
+}
+
+// Alive returns whether the cell at x/y is alive
+func (l *Life) Alive(x, y uint8) bool {
+	return l.cells[l.index(x, y)] > 0
+}
+
+func (l *Life) Update(now time.Time) bool {
+	if l.lastUpdate.IsZero() {
+		// show the seed first
+		l.lastUpdate = now
+		l.dirty = false
+		return true
+	}
+	if now.Sub(l.lastUpdate) < l.speed {
+		changed := l.dirty
+		l.dirty = false
+		return changed
+	}
+	l.lastUpdate = now
+	l.dirty = false
+
+	l.step()
+	if l.isStalled() {
+		l.Reseed()
+		l.dirty = false
+	}
+	return true
+}
+
+func (l *Life) step() {
+	for x := uint8(0); x < l.width; x++ {
+		for y := uint8(0); y < l.height; y++ {
+			i := l.index(x, y)
+			n := l.neighbours(x, y)
+			switch {
+			case l.cells[i] > 0 && (n == 2 || n == 3):
+				l.next[i] = l.cells[i]
+				if l.next[i] < 0xFF {
+					l.next[i]++
+				}
+			case l.cells[i] == 0 && n == 3:
+				l.next[i] = 1
+			default:
+				l.next[i] = 0
+			}
+		}
+	}
+	l.cells, l.next = l.next, l.cells
+}
+
+// neighbours counts the living neighbours, wrapping around at the edges
+func (l *Life) neighbours(x, y uint8) int {
+	n := 0
+	for dx := -1; dx <= 1; dx++ {
+		for dy := -1; dy <= 1; dy++ {
+			if dx == 0 && dy == 0 {
+				continue
+			}
+			nx := uint8((int(x) + dx + int(l.width)) % int(l.width))
+			ny := uint8((int(y) + dy + int(l.height)) % int(l.height))
+			if l.cells[l.index(nx, ny)] > 0 {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// isStalled returns whether the grid died out or repeated one of the last generations for too long
+func (l *Life) isStalled() bool {
+	h := l.hash()
+	if h == 0 {
+		return true
+	}
+	repeated := false
+	for _, prev := range l.history {
+		repeated = repeated || prev == h
+	}
+	copy(l.history[1:], l.history[:lifeHistory-1])
+	l.history[0] = h
+
+	if !repeated {
+		l.stalled = 0
+		return false
+	}
+	l.stalled++
+	return l.stalled > lifeStallAfter
+}
+
+// hash of the living cells, FNV-1a over their indices so grids of any size fit. 0 means no cell is alive.
+func (l *Life) hash() uint64 {
+	alive := false
+	h := uint64(14695981039346656037)
+	for i, c := range l.cells {
+		if c == 0 {
+			continue
+		}
+		alive = true
+		h ^= uint64(i)
+		h *= 1099511628211
+	}
+	if !alive {
+		return 0
+	}
+	return h
+}
+
+func (l *Life) index(x, y uint8) int {
+	return int(x)*int(l.height) + int(y)
+}
+
+// colorOfAge colors young cells blue and shifts them through the color wheel as they age
+func colorOfAge(age uint8) draw.RGB {
+	if age == 0 {
+		return draw.RGB{}
+	}
+	return colorWheel(170 + (age-1)*12)
+}
+
+// Draw shows the bottom left 4x4 cells of the grid
+func (l *Life) Draw(d draw.Display) error {
+	for x := uint8(0); x < 4; x++ {
+		for y := uint8(0); y < 4; y++ {
+			c := draw.RGB{}
+			if x < l.width && y < l.height {
+				c = colorOfAge(l.cells[l.index(x, y)])
+			}
+			l.buf.Set(x, y, c)
+		}
+	}
+	return d.WriteBuffer(&l.buf)
+}
+
+// DrawFrame shows the whole grid, e.g. on several tiled boards
+func (l *Life) DrawFrame(d draw.FrameDisplay, f *draw.Frame) error {
+	for x := uint8(0); x < f.Width; x++ {
+		for y := uint8(0); y < f.Height; y++ {
+			c := draw.RGB{}
+			if x < l.width && y < l.height {
+				c = colorOfAge(l.cells[l.index(x, y)])
+			}
+			f.Set(x, y, c)
+		}
+	}
+	return d.WriteFrame(f)
+}
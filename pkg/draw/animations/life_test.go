@@ -0,0 +1,101 @@
+package animations
+
+import (
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/draw/drawtest"
+)
+
+func newEmptyLife(w, h uint8) *Life {
+	l := NewLifeSize(drawtest.NewRand(), w, h)
+	for i := range l.cells {
+		l.cells[i] = 0
+	}
+	return l
+}
+
+func TestLife_Blinker(t *testing.T) {
+	l := newEmptyLife(6, 6)
+	start := drawtest.Start
+	l.Seed(2, 1, start)
+	l.Seed(2, 2, start)
+	l.Seed(2, 3, start)
+
+	be.Equal(t, l.Update(start), true)
+	be.Equal(t, l.Update(start.Add(lifeDefaultSpeed)), true)
+	be.Equal(t, l.Alive(1, 2), true)
+	be.Equal(t, l.Alive(3, 2), true)
+	be.Equal(t, l.Alive(2, 1), false)
+	// the center survived and aged
+	be.Equal(t, l.cells[l.index(2, 2)], 2)
+}
+
+func TestLife_Wraparound(t *testing.T) {
+	l := newEmptyLife(6, 6)
+	l.Seed(0, 0, drawtest.Start)
+	l.Seed(5, 0, drawtest.Start)
+	l.Seed(0, 5, drawtest.Start)
+	be.Equal(t, l.neighbours(5, 5), 3)
+	be.Equal(t, l.neighbours(0, 0), 2)
+}
+
+func TestLife_ReseedWhenStalled(t *testing.T) {
+	l := newEmptyLife(6, 6)
+	// a block is a still life
+	l.Seed(1, 1, drawtest.Start)
+	l.Seed(1, 2, drawtest.Start)
+	l.Seed(2, 1, drawtest.Start)
+	l.Seed(2, 2, drawtest.Start)
+
+	now := drawtest.Start
+	l.Update(now)
+	// the first generation is new, then it repeats
+	for i := 0; i <= lifeStallAfter; i++ {
+		now = now.Add(lifeDefaultSpeed)
+		l.Update(now)
+		be.Equal(t, l.Alive(1, 1), true)
+		be.Equal(t, l.Alive(3, 3), false)
+	}
+	now = now.Add(lifeDefaultSpeed)
+	l.Update(now)
+	be.Equal(t, l.stalled, 0)
+	be.Equal(t, l.cells[l.index(1, 1)] <= 1, true)
+}
+
+func TestLife_SeedRedraws(t *testing.T) {
+	l := newEmptyLife(4, 4)
+	l.Update(drawtest.Start)
+	be.Equal(t, l.Update(drawtest.Start.Add(time.Millisecond)), false)
+
+	l.Seed(3, 3, drawtest.Start.Add(time.Millisecond))
+	be.Equal(t, l.Update(drawtest.Start.Add(2*time.Millisecond)), true)
+
+	var c draw.Capture
+	be.NoError(t, l.Draw(&c))
+	be.Equal(t, c.Buf[15], colorOfAge(1))
+}
+
+type frameCapture struct {
+	frame draw.Frame
+}
+
+func (f *frameCapture) WriteFrame(fr *draw.Frame) error {
+	f.frame = *fr
+	return nil
+}
+
+func TestLife_DrawFrame(t *testing.T) {
+	l := newEmptyLife(8, 8)
+	l.Seed(7, 6, drawtest.Start)
+
+	var c frameCapture
+	be.NoError(t, l.DrawFrame(&c, draw.NewFrame(8, 8)))
+	be.Equal(t, c.frame.At(7, 6), colorOfAge(1))
+	be.Equal(t, c.frame.At(6, 7), draw.RGB{})
+}
+
+func TestLife_Draw(t *testing.T) {
+	drawtest.Snapshot(t, NewLife(drawtest.NewRand()), 20, 200*time.Millisecond)
+}
@@ -0,0 +1,112 @@
+package animations
+
+import (
+	"time"
+	"trelligo/pkg/draw"
+)
+
+// DefaultMarqueeSpeed is the time per column, slow enough for kids to read along
+const DefaultMarqueeSpeed = 150 * time.Millisecond
+
+// Marquee scrolls text from right to left across the display. Characters missing from the font are skipped.
+type Marquee struct {
+	buf     draw.Buffer4x4
+	columns []uint8
+	color   draw.RGB
+	speed   time.Duration
+	loop    bool
+
+	offset     int
+	lastUpdate time.Time
+	started    bool
+	done       bool
+}
+
+func NewMarquee(text string, color draw.RGB) *Marquee {
+	m := &Marquee{
+		color: color,
+		speed: DefaultMarqueeSpeed,
+	}
+	m.SetText(text)
+	return m
+}
+
+// SetText replaces the text and starts scrolling it from the right edge
+func (m *Marquee) SetText(text string) {
+	m.columns = m.columns[:0]
+	for _, r := range text {
+		g, ok := draw.GlyphOf(r)
+		if !ok {
+			continue
+		}
+		if len(m.columns) > 0 {
+			m.columns = append(m.columns, 0)
+		}
+		m.columns = append(m.columns, g[:]...)
+	}
+	m.offset = 0
+	m.started = false
+	m.done = false
+}
+
+func (m *Marquee) SetColor(c draw.RGB) {
+	m.color = c
+	m.started = false
+}
+
+// SetSpeed sets the time per column
+func (m *Marquee) SetSpeed(d time.Duration) {
+	m.speed = d
+}
+
+// SetLoop makes the text start over once it scrolled out of the display
+func (m *Marquee) SetLoop(loop bool) {
+	m.loop = loop
+}
+
+// Done returns whether the text scrolled out of the display, it never is when looping
+func (m *Marquee) Done() bool {
+	return m.done
+}
+
+func (m *Marquee) Update(now time.Time) bool {
+	if m.done {
+		return false
+	}
+	if !m.started {
+		m.started = true
+		m.lastUpdate = now
+		m.render()
+		return true
+	}
+	if now.Sub(m.lastUpdate) < m.speed {
+		return false
+	}
+	m.lastUpdate = now
+
+	m.offset++
+	// the text starts right of the display and ends once its last column left it
+	if m.offset > len(m.columns)+4 {
+		if !m.loop {
+			m.done = true
+			return false
+		}
+		m.offset = 0
+	}
+	m.render()
+	return true
+}
+
+func (m *Marquee) render() {
+	m.buf = draw.Buffer4x4{}
+	for x := 0; x < 4; x++ {
+		i := m.offset + x - 4
+		if i >= 0 && i < len(m.columns) {
+			m.buf.DrawColumn(x, m.columns[i], m.color)
+		}
+	}
+}
+
+func (m *Marquee) Draw(d draw.Display) error {
+	return d.WriteBuffer(&m.buf)
+}
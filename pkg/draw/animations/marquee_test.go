@@ -0,0 +1,36 @@
+package animations
+
+import (
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/draw"
+)
+
+func TestMarquee(t *testing.T) {
+	start := time.Unix(0, 0)
+	m := NewMarquee("1", red)
+
+	var c draw.Capture
+	frames := 0
+	for now := start; !m.Done(); now = now.Add(DefaultMarqueeSpeed) {
+		if m.Update(now) {
+			frames++
+			be.NoError(t, m.Draw(&c))
+		}
+		if frames == 2 {
+			// the glyph's left column entered at x=3
+			be.Equal(t, c.Buf[4*3+2], red)
+			be.Equal(t, c.Buf[4*3+1], draw.RGB{})
+			be.Equal(t, c.Buf[4*2+2], draw.RGB{})
+		}
+	}
+	// blank start, 3 columns in, 4 columns out
+	be.Equal(t, frames, 8)
+	be.Equal(t, c.Buf, draw.Buffer4x4{})
+}
+
+func TestMarquee_SkipsUnknown(t *testing.T) {
+	m := NewMarquee("E?3", red)
+	be.Equal(t, len(m.columns), 7)
+}
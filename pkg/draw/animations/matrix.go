@@ -41,9 +41,9 @@ func (m *matrix) initScrollLine(i int) {
 	}
 }
 
-func (m *matrix) Update(now time.Time) {
+func (m *matrix) Update(now time.Time) bool {
 	if now.Sub(m.lastUpdated) < time.Millisecond*100 {
-		return
+		return false
 	}
 	m.lastUpdated = now
 	m.buf = draw.Buffer4x4{}
@@ -56,6 +56,7 @@ func (m *matrix) Update(now time.Time) {
 		}
 		drawLine(&m.buf, &s, uint8(column))
 	}
+	return true
 }
 
 func drawLine(buf *draw.Buffer4x4, s *scrollLine, col uint8) {
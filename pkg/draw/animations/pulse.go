@@ -0,0 +1,56 @@
+package animations
+
+import (
+	"time"
+	"trelligo/pkg/draw"
+)
+
+type pulse struct {
+	buf     draw.Buffer4x4
+	drawn   bool
+	color   draw.RGB
+	mask    draw.Mask
+	period  time.Duration
+	started time.Time
+}
+
+// NewPulse fades the keys within the mask in and out of the color once per period
+func NewPulse(color draw.RGB, mask draw.Mask, period time.Duration) draw.Animation {
+	return &pulse{color: color, mask: mask, period: period}
+}
+
+func (p *pulse) Update(now time.Time) bool {
+	if p.started.IsZero() {
+		p.started = now
+	}
+
+	if p.period <= 0 {
+		return p.fill(p.color)
+	}
+
+	// triangle wave from 0 up to 0x1FE and back
+	phase := int(now.Sub(p.started) % p.period * 0x1FE / p.period)
+	level := phase
+	if level > 0xFF {
+		level = 0x1FE - level
+	}
+
+	return p.fill(draw.Blend(draw.RGB{}, p.color, uint8(level)))
+}
+
+// fill sets the color of all keys within the mask, returns whether the frame changed
+func (p *pulse) fill(c draw.RGB) bool {
+	changed := !p.drawn
+	p.drawn = true
+	for i := range p.buf {
+		if p.mask.Contains(uint8(i/4), uint8(i%4)) && p.buf[i] != c {
+			p.buf[i] = c
+			changed = true
+		}
+	}
+	return changed
+}
+
+func (p *pulse) Draw(d draw.Display) error {
+	return d.WriteBuffer(&p.buf)
+}
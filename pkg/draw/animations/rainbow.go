@@ -18,10 +18,10 @@ func NewInfinityRainbow() draw.Animation {
 func (i *infinityRainbow) Draw(display draw.Display) error {
 	return display.WriteBuffer(&i.buf)
 }
-func (i *infinityRainbow) Update(now time.Time) {
+func (i *infinityRainbow) Update(now time.Time) bool {
 
 	if now.Sub(i.lastUpdate) < 50*time.Millisecond {
-		return
+		return false
 	}
 	i.lastUpdate = now
 
@@ -31,4 +31,5 @@ func (i *infinityRainbow) Update(now time.Time) {
 	i.buf[p] = color
 
 	i.iteration++
+	return true
 }
@@ -26,10 +26,10 @@ func NewRandomBlink(r *rand.Rand) draw.Animation {
 func (r *randomBlink) Draw(display draw.Display) error {
 	return display.WriteBuffer(&r.buf)
 }
-func (r *randomBlink) Update(now time.Time) {
+func (r *randomBlink) Update(now time.Time) bool {
 
 	if now.Sub(r.lastUpdate) < 100*time.Millisecond {
-		return
+		return false
 	}
 	r.lastUpdate = now
 
@@ -38,4 +38,5 @@ func (r *randomBlink) Update(now time.Time) {
 	p := (v >> 8) % 16
 	r.buf[p] = color
 
+	return true
 }
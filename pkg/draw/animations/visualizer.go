@@ -0,0 +1,130 @@
+package animations
+
+import (
+	"time"
+	"trelligo/pkg/draw"
+)
+
+// DefaultBPM is the tempo of the beat clock, a typical tempo of children's songs
+const DefaultBPM = 100
+
+// visualizerFloor is the level keys decay to between beats
+const visualizerFloor = 0x40
+
+// Visualizer pulses rings of keys in the color of the playing folder to a beat clock, and shows an idle animation
+// while nothing plays. The DFPlayer gives no access to the audio, so the beat is a fixed tempo clock restarted with
+// every track.
+type Visualizer struct {
+	idle    draw.Animation
+	playing bool
+	folder  uint8
+	track   uint16
+	beat    time.Duration
+
+	beatStart time.Time
+	restart   bool
+	dirty     bool
+	buf       draw.Buffer4x4
+}
+
+// NewVisualizer creates a visualizer that shows the idle animation while stopped
+func NewVisualizer(idle draw.Animation) *Visualizer {
+	v := &Visualizer{idle: idle}
+	v.SetBPM(DefaultBPM)
+	return v
+}
+
+// SetBPM sets the tempo of the beat clock
+func (v *Visualizer) SetBPM(bpm int) {
+	if bpm <= 0 {
+		bpm = DefaultBPM
+	}
+	v.beat = time.Minute / time.Duration(bpm)
+}
+
+// SetPlaying switches between the pulse and the idle animation
+func (v *Visualizer) SetPlaying(playing bool) {
+	if playing && !v.playing {
+		v.restart = true
+	}
+	v.playing = playing
+	v.dirty = true
+}
+
+// SetFolder sets the folder playing, it picks the color
+func (v *Visualizer) SetFolder(folder uint8) {
+	v.folder = folder
+	v.dirty = true
+}
+
+// SetTrack sets the track playing, the beat restarts and the direction of the rings alternates with every track
+func (v *Visualizer) SetTrack(track uint16) {
+	if track != v.track {
+		v.restart = true
+	}
+	v.track = track
+}
+
+func (v *Visualizer) Playing() bool {
+	return v.playing
+}
+
+// FolderColor is the color of a folder, spread around the color wheel
+func FolderColor(folder uint8) draw.RGB {
+	return colorWheel(folder * 28)
+}
+
+func (v *Visualizer) Update(now time.Time) bool {
+	changed := v.dirty
+	v.dirty = false
+	if !v.playing {
+		return v.idle.Update(now) || changed
+	}
+
+	if v.restart || v.beatStart.IsZero() {
+		v.restart = false
+		v.beatStart = now
+	}
+
+	phase := now.Sub(v.beatStart) % v.beat
+	c := FolderColor(v.folder)
+	for x := uint8(0); x < 4; x++ {
+		for y := uint8(0); y < 4; y++ {
+			// the inner ring peaks on the beat, the outer one a quarter beat later, or the other way round
+			ring := ringOf(x, y)
+			if v.track%2 == 1 {
+				ring = 1 - ring
+			}
+			p := phase - time.Duration(ring)*v.beat/4
+			if p < 0 {
+				p += v.beat
+			}
+			v.buf.Set(x, y, draw.Blend(draw.RGB{}, c, decay(p, v.beat)))
+		}
+	}
+	return true
+}
+
+// ringOf returns 0 for the inner four keys and 1 for the outer ones
+func ringOf(x, y uint8) int {
+	if x >= 1 && x <= 2 && y >= 1 && y <= 2 {
+		return 0
+	}
+	return 1
+}
+
+// decay is the level at the time p after a beat, falling linearly from full to the floor within half a beat
+func decay(p, beat time.Duration) uint8 {
+	half := beat / 2
+	if p >= half {
+		return visualizerFloor
+	}
+	return uint8(0xFF - (0xFF-visualizerFloor)*int64(p)/int64(half))
+}
+
+func (v *Visualizer) Draw(d draw.Display) error {
+	if !v.playing {
+		return v.idle.Draw(d)
+	}
+	return d.WriteBuffer(&v.buf)
+}
@@ -0,0 +1,35 @@
+package animations
+
+import (
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/draw/drawtest"
+)
+
+func TestVisualizer_Idle(t *testing.T) {
+	idle := newFill(red)
+	v := NewVisualizer(idle)
+
+	var c draw.Capture
+	be.Equal(t, v.Update(drawtest.Start), true)
+	be.NoError(t, v.Draw(&c))
+	be.Equal(t, c.Buf[0], red)
+
+	v.SetPlaying(true)
+	v.SetFolder(3)
+	be.Equal(t, v.Update(drawtest.Start), true)
+	be.NoError(t, v.Draw(&c))
+	// on the beat the inner ring is at full brightness, the outer one waits for its quarter beat
+	be.Equal(t, c.Buf[4*1+1], FolderColor(3))
+	be.Equal(t, c.Buf[0], draw.Blend(draw.RGB{}, FolderColor(3), visualizerFloor))
+}
+
+func TestVisualizer_Draw(t *testing.T) {
+	v := NewVisualizer(NewPulse(draw.RGB{B: 0x40}, draw.MaskAll, time.Second))
+	v.SetPlaying(true)
+	v.SetFolder(1)
+	v.SetTrack(2)
+	drawtest.Snapshot(t, v, 12, 50*time.Millisecond)
+}
@@ -0,0 +1,121 @@
+// Package drawtest provides golden-frame snapshot testing for animations. Run the tests with UPDATE_GOLDEN=1 to
+// rewrite the golden files in testdata after intended changes.
+package drawtest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/shims/rand"
+)
+
+// updateEnv is the environment variable that rewrites the golden files. It is not a flag, so importing tests can
+// define their own flags without a conflict.
+const updateEnv = "UPDATE_GOLDEN"
+
+// Start is the deterministic time of the first frame
+var Start = time.Unix(0, 0)
+
+// NewRand returns a seeded random number generator so snapshots are reproducible
+func NewRand() *rand.Rand {
+	return rand.New(rand.NewSource(1))
+}
+
+// Record drives the animation with a deterministic clock, once per step, and returns the frame shown after each step
+func Record(t testing.TB, a draw.Animation, steps int, step time.Duration) []draw.Buffer4x4 {
+	t.Helper()
+
+	var c draw.Capture
+	frames := make([]draw.Buffer4x4, 0, steps)
+	for i := 0; i < steps; i++ {
+		if a.Update(Start.Add(time.Duration(i) * step)) {
+			err := a.Draw(&c)
+			if err != nil {
+				t.Fatalf("failed to draw frame %d: %v", i, err)
+			}
+		}
+		frames = append(frames, c.Buf)
+	}
+	return frames
+}
+
+// Snapshot records the animation and compares the frames against testdata/<test name>.golden
+func Snapshot(t testing.TB, a draw.Animation, steps int, step time.Duration) {
+	t.Helper()
+	Golden(t, Format(Record(t, a, steps, step), step))
+}
+
+// Format renders frames as text, one RRGGBB value per key with the top row first
+func Format(frames []draw.Buffer4x4, step time.Duration) []byte {
+	var buf bytes.Buffer
+	for i, f := range frames {
+		fmt.Fprintf(&buf, "# frame %d at %s\n", i, time.Duration(i)*step)
+		for y := 3; y >= 0; y-- {
+			cells := make([]string, 4)
+			for x := 0; x < 4; x++ {
+				c := f[4*x+y]
+				cells[x] = fmt.Sprintf("%02x%02x%02x", c.R, c.G, c.B)
+			}
+			buf.WriteString(strings.Join(cells, " "))
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes()
+}
+
+// Golden compares got against testdata/<test name>.golden, or rewrites the file with UPDATE_GOLDEN=1
+func Golden(t testing.TB, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", strings.ReplaceAll(t.Name(), "/", "_")+".golden")
+	if os.Getenv(updateEnv) == "1" {
+		err := os.MkdirAll("testdata", 0o755)
+		if err == nil {
+			err = os.WriteFile(path, got, 0o644)
+		}
+		if err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file, run with UPDATE_GOLDEN=1 to create it: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("frames differ from %s, run with UPDATE_GOLDEN=1 if intended:\n%s", path, diff(want, got))
+	}
+}
+
+// diff lists the first differing lines
+func diff(want, got []byte) string {
+	wl := strings.Split(string(want), "\n")
+	gl := strings.Split(string(got), "\n")
+	var sb strings.Builder
+	shown := 0
+	for i := 0; i < len(wl) || i < len(gl); i++ {
+		var w, g string
+		if i < len(wl) {
+			w = wl[i]
+		}
+		if i < len(gl) {
+			g = gl[i]
+		}
+		if w == g {
+			continue
+		}
+		fmt.Fprintf(&sb, "line %d:\n  want: %s\n  got:  %s\n", i+1, w, g)
+		shown++
+		if shown == 5 {
+			sb.WriteString("...\n")
+			break
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+package draw
+
+import "strings"
+
+// Glyph is a 3x4 character, one nibble per column from left to right, bit 3 is the top row
+type Glyph [3]uint8
+
+const GlyphWidth = 3
+
+// glyphChars are the characters of the font, lower and upper case are interchangeable
+const glyphChars = "0123456789ACdEFHLoPrUV- "
+
+var glyphs = [...]Glyph{
+	{0xF, 0x9, 0xF}, // 0
+	{0x5, 0xF, 0x1}, // 1
+	{0x9, 0xB, 0x5}, // 2
+	{0x9, 0xD, 0xF}, // 3
+	{0xE, 0x2, 0xF}, // 4
+	{0xD, 0xD, 0xA}, // 5
+	{0xF, 0x5, 0x7}, // 6
+	{0x8, 0xB, 0xC}, // 7
+	{0xF, 0xD, 0xF}, // 8
+	{0xE, 0xA, 0xF}, // 9
+	{0x7, 0xA, 0x7}, // A
+	{0xF, 0x9, 0x9}, // C
+	{0x7, 0x5, 0xF}, // d
+	{0xF, 0xD, 0x9}, // E
+	{0xF, 0xA, 0x8}, // F
+	{0xF, 0x4, 0xF}, // H
+	{0xF, 0x1, 0x1}, // L
+	{0x7, 0x5, 0x7}, // o
+	{0xF, 0xA, 0xE}, // P
+	{0x7, 0x4, 0x0}, // r
+	{0xF, 0x1, 0xF}, // U
+	{0xE, 0x1, 0xE}, // V
+	{0x4, 0x4, 0x4}, // -
+	{0x0, 0x0, 0x0}, // space
+}
+
+// GlyphOf returns the glyph of a character, ok is false if the font lacks it
+func GlyphOf(r rune) (g Glyph, ok bool) {
+	i := strings.IndexRune(glyphChars, r)
+	if i < 0 && r < 0x80 {
+		i = strings.IndexRune(glyphChars, swapCase(r))
+	}
+	if i < 0 {
+		return g, false
+	}
+	return glyphs[i], true
+}
+
+func swapCase(r rune) rune {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return r - 'a' + 'A'
+	case r >= 'A' && r <= 'Z':
+		return r - 'A' + 'a'
+	}
+	return r
+}
+
+// DrawColumn draws a glyph column at x, bit 3 of bits is the top row. Columns outside the buffer are ignored.
+func (p *Buffer4x4) DrawColumn(x int, bits uint8, c RGB) {
+	if x < 0 || x > 3 {
+		return
+	}
+	for y := uint8(0); y < 4; y++ {
+		if bits&(1<<y) != 0 {
+			p.Set(uint8(x), y, c)
+		}
+	}
+}
+
+// DrawGlyph draws a glyph with its left column at x, parts outside the buffer are clipped
+func (p *Buffer4x4) DrawGlyph(x int, g Glyph, c RGB) {
+	for i, bits := range g {
+		p.DrawColumn(x+i, bits, c)
+	}
+}
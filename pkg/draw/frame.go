@@ -0,0 +1,29 @@
+package draw
+
+// Frame is a buffer of any size, e.g. for several tiled boards. Pixels are ordered column by column like in
+// Buffer4x4, which matches neotrellis.MultiTrellis.PixelOffset.
+type Frame struct {
+	Width, Height uint8
+	Pix           []RGB
+}
+
+func NewFrame(width, height uint8) *Frame {
+	return &Frame{
+		Width:  width,
+		Height: height,
+		Pix:    make([]RGB, int(width)*int(height)),
+	}
+}
+
+func (f *Frame) Set(x, y uint8, c RGB) {
+	f.Pix[int(x)*int(f.Height)+int(y)] = c
+}
+
+func (f *Frame) At(x, y uint8) RGB {
+	return f.Pix[int(x)*int(f.Height)+int(y)]
+}
+
+// FrameDisplay shows frames larger than a single board
+type FrameDisplay interface {
+	WriteFrame(f *Frame) error
+}
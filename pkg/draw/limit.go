@@ -0,0 +1,103 @@
+package draw
+
+// Rough NeoPixel current draw: ~20mA per channel at full duty cycle plus ~1mA per pixel for the driver itself
+const (
+	milliampsPerChannel = 20
+	idleMilliampsPerLed = 1
+)
+
+// DefaultBudget is a current budget that a typical USB power bank supplies to the NeoTrellis while leaving enough
+// for the DFPlayer and the microcontroller
+const DefaultBudget = 350
+
+// Limiter scales frames down to a global brightness and, if their estimated current still exceeds the budget, further
+// down until it fits.
+type Limiter struct {
+	brightness uint8
+	budget     int
+}
+
+// NewLimiter creates a limiter at full brightness with the given budget in mA, a budget of 0 means unlimited
+func NewLimiter(budget int) *Limiter {
+	return &Limiter{
+		brightness: 0xFF,
+		budget:     budget,
+	}
+}
+
+// SetBrightness sets the global brightness, 0xFF leaves colors unchanged
+func (l *Limiter) SetBrightness(brightness uint8) {
+	l.brightness = brightness
+}
+
+func (l *Limiter) Brightness() uint8 {
+	return l.brightness
+}
+
+// SetBudget sets the current budget in mA, 0 means unlimited
+func (l *Limiter) SetBudget(budget int) {
+	l.budget = budget
+}
+
+func (l *Limiter) Budget() int {
+	return l.budget
+}
+
+// EstimateMilliamps estimates the current the LEDs draw when showing the frame. Colors MUST already be gamma
+// corrected, the current follows the duty cycle.
+func EstimateMilliamps(b *Buffer4x4) int {
+	return estimateMilliamps(b[:])
+}
+
+func estimateMilliamps(b []RGB) int {
+	return len(b)*idleMilliampsPerLed + channelSum(b)*milliampsPerChannel/0xFF
+}
+
+// Limit applies brightness and budget to a gamma corrected buffer in place
+func (l *Limiter) Limit(b *Buffer4x4) {
+	l.limit(b[:])
+}
+
+// LimitFrame applies brightness and budget to a gamma corrected frame in place, the budget covers all boards
+func (l *Limiter) LimitFrame(f *Frame) {
+	l.limit(f.Pix)
+}
+
+func (l *Limiter) limit(b []RGB) {
+	if l.brightness < 0xFF {
+		for i := range b {
+			b[i] = scale(b[i], int(l.brightness), 0xFF)
+		}
+	}
+
+	if l.budget <= 0 {
+		return
+	}
+	sum := channelSum(b)
+	allowed := (l.budget - len(b)*idleMilliampsPerLed) * 0xFF / milliampsPerChannel
+	if allowed < 0 {
+		allowed = 0
+	}
+	if sum <= allowed {
+		return
+	}
+	for i := range b {
+		b[i] = scale(b[i], allowed, sum)
+	}
+}
+
+func channelSum(b []RGB) int {
+	sum := 0
+	for _, c := range b {
+		sum += int(c.R) + int(c.G) + int(c.B)
+	}
+	return sum
+}
+
+func scale(c RGB, num, denom int) RGB {
+	return RGB{
+		R: uint8(int(c.R) * num / denom),
+		G: uint8(int(c.G) * num / denom),
+		B: uint8(int(c.B) * num / denom),
+	}
+}
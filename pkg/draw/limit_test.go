@@ -0,0 +1,42 @@
+package draw
+
+import (
+	"testing"
+	"trelligo/pkg/be"
+)
+
+func white() *Buffer4x4 {
+	var b Buffer4x4
+	for i := range b {
+		b[i] = RGB{0xFF, 0xFF, 0xFF}
+	}
+	return &b
+}
+
+func TestLimiter_Budget(t *testing.T) {
+	b := white()
+	be.Equal(t, EstimateMilliamps(b), 16+16*60)
+
+	l := NewLimiter(DefaultBudget)
+	l.Limit(b)
+	be.Equal(t, EstimateMilliamps(b) <= DefaultBudget, true)
+	be.Equal(t, b[0].R > 0, true)
+}
+
+func TestLimiter_WithinBudget(t *testing.T) {
+	var b Buffer4x4
+	b[3] = RGB{0xFF, 0, 0x10}
+
+	l := NewLimiter(DefaultBudget)
+	l.Limit(&b)
+	be.Equal(t, b[3], RGB{0xFF, 0, 0x10})
+}
+
+func TestLimiter_Brightness(t *testing.T) {
+	b := white()
+
+	l := NewLimiter(0)
+	l.SetBrightness(0x80)
+	l.Limit(b)
+	be.Equal(t, b[15], RGB{0x80, 0x80, 0x80})
+}
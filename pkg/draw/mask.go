@@ -0,0 +1,62 @@
+package draw
+
+// Mask selects a region of keys, one bit per pixel in buffer order
+type Mask uint16
+
+const MaskAll Mask = 0xFFFF
+
+// MaskOf creates a mask of a single key
+func MaskOf(x, y uint8) Mask {
+	return 1 << (4*x + y)
+}
+
+// MaskRow selects all keys of row y
+func MaskRow(y uint8) Mask {
+	return 0x1111 << y
+}
+
+// MaskColumn selects all keys of column x
+func MaskColumn(x uint8) Mask {
+	return 0xF << (4 * x)
+}
+
+// Contains returns whether the key at x/y is selected
+func (m Mask) Contains(x, y uint8) bool {
+	return m.has(4*x + y)
+}
+
+func (m Mask) has(offset uint8) bool {
+	return m&(1<<offset) != 0
+}
+
+// Blend mixes the color over the base, alpha 0xFF returns over
+func Blend(base, over RGB, alpha uint8) RGB {
+	a := int(alpha)
+	mix := func(b, o uint8) uint8 {
+		return uint8((int(b)*(0xFF-a) + int(o)*a) / 0xFF)
+	}
+	return RGB{
+		R: mix(base.R, over.R),
+		G: mix(base.G, over.G),
+		B: mix(base.B, over.B),
+	}
+}
+
+// BlendBuffer blends over onto base in place, only keys within the mask are changed
+func BlendBuffer(base, over *Buffer4x4, alpha uint8, mask Mask) {
+	for i := range base {
+		if mask.has(uint8(i)) {
+			base[i] = Blend(base[i], over[i], alpha)
+		}
+	}
+}
+
+// Capture is a Display that keeps the last written frame, e.g. to compose animations
+type Capture struct {
+	Buf Buffer4x4
+}
+
+func (c *Capture) WriteBuffer(b *Buffer4x4) error {
+	c.Buf = *b
+	return nil
+}
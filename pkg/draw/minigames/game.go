@@ -0,0 +1,64 @@
+package minigames
+
+import (
+	"time"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/rbuf"
+)
+
+type EventType uint8
+
+const (
+	Unknown EventType = iota
+	KeyDown
+	KeyUp
+)
+
+type Event interface {
+	X() uint8
+	Y() uint8
+	Type() EventType
+}
+
+// Game is stepped by a Runner. Update consumes the pending events from the buffer.
+type Game interface {
+	Update(now time.Time, events *rbuf.RingBuffer[Event])
+	Draw(d draw.Display) error
+	Score() int
+	Level() int
+	Over() bool
+}
+
+// Sound plays short effects over the music, e.g. dfplayer.Player advertisements from SD:/ADVERT
+type Sound interface {
+	Advertise(file uint16) error
+}
+
+// Sound effects, the numbers are the files in SD:/ADVERT, e.g. SD:/ADVERT/0001.mp3
+const (
+	SoundHit      uint16 = 1
+	SoundMiss     uint16 = 2
+	SoundLevelUp  uint16 = 3
+	SoundGameOver uint16 = 4
+)
+
+type keyEvent struct {
+	x, y uint8
+	t    EventType
+}
+
+func NewKeyEvent(x, y uint8, t EventType) Event {
+	return keyEvent{x: x, y: y, t: t}
+}
+
+func (k keyEvent) X() uint8 {
+	return k.x
+}
+
+func (k keyEvent) Y() uint8 {
+	return k.y
+}
+
+func (k keyEvent) Type() EventType {
+	return k.t
+}
@@ -0,0 +1,67 @@
+package minigames
+
+import (
+	"strconv"
+	"time"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/draw/animations"
+	"trelligo/pkg/rbuf"
+	"trelligo/pkg/seesaw/keypad"
+)
+
+const eventBufferSize = 16
+
+var colorScore = draw.RGB{R: 0xFF, G: 0xC0, B: 0}
+
+// Runner feeds key events into a game, steps it and shows the score once the game is over
+type Runner struct {
+	game    Game
+	display draw.Display
+	events  rbuf.RingBuffer[Event]
+	score   *animations.Marquee
+}
+
+func NewRunner(g Game, d draw.Display) *Runner {
+	return &Runner{
+		game:    g,
+		display: d,
+		events:  rbuf.New[Event](eventBufferSize),
+	}
+}
+
+// HandleKey queues a key edge for the game, it matches neotrellis.Device.SetKeyHandleFunc. Events are dropped while
+// the game doesn't keep up.
+func (r *Runner) HandleKey(x, y uint8, e keypad.Edge) error {
+	t := Unknown
+	switch e {
+	case keypad.EdgeRising:
+		t = KeyDown
+	case keypad.EdgeFalling:
+		t = KeyUp
+	}
+	_ = r.events.Write(NewKeyEvent(x, y, t))
+	return nil
+}
+
+// Step updates and draws the game, once it is over the score scrolls by. Returns true when the score was shown.
+func (r *Runner) Step(now time.Time) (bool, error) {
+	if r.score == nil && !r.game.Over() {
+		r.game.Update(now, &r.events)
+		return false, r.game.Draw(r.display)
+	}
+
+	if r.score == nil {
+		r.score = animations.NewMarquee(strconv.Itoa(r.game.Score()), colorScore)
+	}
+	if r.score.Update(now) {
+		err := r.score.Draw(r.display)
+		if err != nil {
+			return false, err
+		}
+	}
+	return r.score.Done(), nil
+}
+
+func (r *Runner) Game() Game {
+	return r.game
+}
@@ -0,0 +1,235 @@
+package minigames
+
+import (
+	"time"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/rbuf"
+	"trelligo/pkg/shims/rand"
+)
+
+// SoundTone is the tone of the first pad, every pad has its own, e.g. pad 2 plays SD:/ADVERT/0103.mp3
+const SoundTone uint16 = 101
+
+type Difficulty uint8
+
+const (
+	// SimonEasy has four pads of 2x2 keys, like the original
+	SimonEasy Difficulty = iota
+	// SimonHard makes every key a pad of its own
+	SimonHard
+)
+
+type SimonConfig struct {
+	Difficulty Difficulty
+	// Step is how long each pad of the sequence is shown, later rounds get faster down to half of it
+	Step time.Duration
+	// InputTimeout ends the game if no key is pressed for that long
+	InputTimeout time.Duration
+}
+
+func DefaultSimonConfig() SimonConfig {
+	return SimonConfig{
+		Difficulty:   SimonEasy,
+		Step:         700 * time.Millisecond,
+		InputTimeout: 5 * time.Second,
+	}
+}
+
+const (
+	simonPause = time.Second
+	simonFlash = 250 * time.Millisecond
+)
+
+// colors of the pads of each quadrant, like the original
+var simonColors = [4]draw.RGB{
+	{R: 0, G: 0xFF, B: 0},
+	{R: 0xFF, G: 0, B: 0},
+	{R: 0, G: 0, B: 0xFF},
+	{R: 0xFF, G: 0xC0, B: 0},
+}
+
+type simonState uint8
+
+const (
+	simonPausing simonState = iota
+	simonShowing
+	simonInput
+	simonOver
+)
+
+// Simon plays a sequence of pads that grows by one each round and has to be repeated
+type Simon struct {
+	cfg   SimonConfig
+	rnd   *rand.Rand
+	sound Sound
+
+	sequence []uint8
+	rounds   int
+	state    simonState
+	pos      int
+	until    time.Time
+	started  bool
+
+	lit      int
+	litUntil time.Time
+
+	buf draw.Buffer4x4
+}
+
+func NewSimon(r *rand.Rand, cfg SimonConfig) *Simon {
+	return &Simon{
+		cfg: cfg,
+		rnd: r,
+		lit: -1,
+	}
+}
+
+// SetSound enables the tones of the pads and sound effects. Playing is best effort, failures are ignored.
+func (s *Simon) SetSound(sound Sound) {
+	s.sound = sound
+}
+
+// Score is the length of the longest sequence repeated correctly
+func (s *Simon) Score() int {
+	return s.rounds
+}
+
+// Level is the length of the current sequence
+func (s *Simon) Level() int {
+	return len(s.sequence)
+}
+
+func (s *Simon) Over() bool {
+	return s.state == simonOver
+}
+
+func (s *Simon) Update(now time.Time, events *rbuf.RingBuffer[Event]) {
+	if !s.started {
+		s.started = true
+		s.until = now.Add(simonPause)
+	}
+	if s.lit >= 0 && !now.Before(s.litUntil) {
+		s.lit = -1
+	}
+
+	for {
+		e, err := events.Read()
+		if err != nil {
+			break
+		}
+		if e.Type() == KeyDown && s.state == simonInput {
+			s.press(s.padOf(e.X(), e.Y()), now)
+		}
+	}
+
+	switch s.state {
+	case simonPausing:
+		if now.Before(s.until) {
+			return
+		}
+		s.sequence = append(s.sequence, uint8(s.rnd.Intn(s.pads())))
+		s.state = simonShowing
+		s.pos = 0
+		s.showPad(now)
+	case simonShowing:
+		if now.Before(s.until) {
+			return
+		}
+		s.pos++
+		if s.pos < len(s.sequence) {
+			s.showPad(now)
+			return
+		}
+		s.state = simonInput
+		s.pos = 0
+		s.until = now.Add(s.cfg.InputTimeout)
+	case simonInput:
+		if !now.Before(s.until) {
+			s.gameOver()
+		}
+	}
+}
+
+// showPad lights the pad at pos for most of the step
+func (s *Simon) showPad(now time.Time) {
+	step := s.step()
+	s.light(s.sequence[s.pos], now, step-step/4)
+	s.until = now.Add(step)
+}
+
+func (s *Simon) press(pad uint8, now time.Time) {
+	s.light(pad, now, simonFlash)
+	if pad != s.sequence[s.pos] {
+		s.gameOver()
+		return
+	}
+	s.pos++
+	s.until = now.Add(s.cfg.InputTimeout)
+	if s.pos == len(s.sequence) {
+		s.rounds++
+		s.state = simonPausing
+		s.until = now.Add(simonPause)
+	}
+}
+
+func (s *Simon) gameOver() {
+	s.state = simonOver
+	s.play(SoundGameOver)
+}
+
+func (s *Simon) light(pad uint8, now time.Time, d time.Duration) {
+	s.lit = int(pad)
+	s.litUntil = now.Add(d)
+	s.play(SoundTone + uint16(pad))
+}
+
+// step gets shorter by 1/16 per round down to half of the configured step
+func (s *Simon) step() time.Duration {
+	d := s.cfg.Step
+	for i := 1; i < len(s.sequence) && d > s.cfg.Step/2; i++ {
+		d -= d / 16
+	}
+	if d < s.cfg.Step/2 {
+		return s.cfg.Step / 2
+	}
+	return d
+}
+
+func (s *Simon) pads() int {
+	if s.cfg.Difficulty == SimonHard {
+		return 16
+	}
+	return 4
+}
+
+// padOf maps a key to its pad, easy pads are the quadrants
+func (s *Simon) padOf(x, y uint8) uint8 {
+	if s.cfg.Difficulty == SimonHard {
+		return 4*x + y
+	}
+	return (x/2)*2 + y/2
+}
+
+func (s *Simon) play(file uint16) {
+	if s.sound == nil {
+		return
+	}
+	_ = s.sound.Advertise(file)
+}
+
+// Draw shows all pads dimmed and the lit pad at full brightness
+func (s *Simon) Draw(d draw.Display) error {
+	for x := uint8(0); x < 4; x++ {
+		for y := uint8(0); y < 4; y++ {
+			c := simonColors[(x/2)*2+y/2]
+			if int(s.padOf(x, y)) != s.lit {
+				c = draw.Blend(draw.RGB{}, c, 0x20)
+			}
+			if s.state == simonOver {
+				c = draw.RGB{}
+			}
+			s.buf.Set(x, y, c)
+		}
+	}
+	return d.WriteBuffer(&s.buf)
+}
@@ -0,0 +1,88 @@
+package minigames
+
+import (
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/prng"
+	"trelligo/pkg/rbuf"
+)
+
+func newTestSimon(t *testing.T, cfg SimonConfig) (*Simon, *sounds) {
+	r, err := prng.New(func() (uint32, error) { return 42, nil })
+	be.NoError(t, err)
+	s := NewSimon(r, cfg)
+	snd := &sounds{}
+	s.SetSound(snd)
+	return s, snd
+}
+
+// keyOf returns a key of the pad
+func keyOf(s *Simon, pad uint8) (uint8, uint8) {
+	if s.cfg.Difficulty == SimonHard {
+		return pad / 4, pad % 4
+	}
+	return (pad / 2) * 2, (pad % 2) * 2
+}
+
+// runUntilInput steps the game in 100ms steps until it waits for input
+func runUntilInput(s *Simon, now time.Time, events *rbuf.RingBuffer[Event]) time.Time {
+	for i := 0; i < 1000 && s.state != simonInput; i++ {
+		now = now.Add(100 * time.Millisecond)
+		s.Update(now, events)
+	}
+	return now
+}
+
+func TestSimon_Rounds(t *testing.T) {
+	s, snd := newTestSimon(t, DefaultSimonConfig())
+	events := rbuf.New[Event](32)
+
+	now := time.Unix(0, 0)
+	s.Update(now, &events)
+	for round := 1; round <= 5; round++ {
+		now = runUntilInput(s, now, &events)
+		be.Equal(t, s.Level(), round)
+
+		// every pad of the sequence was played as tone
+		be.Equal(t, snd.played[len(snd.played)-1], SoundTone+uint16(s.sequence[round-1]))
+
+		for _, pad := range s.sequence {
+			x, y := keyOf(s, pad)
+			be.NoError(t, events.Write(NewKeyEvent(x, y, KeyDown)))
+			be.NoError(t, events.Write(NewKeyEvent(x, y, KeyUp)))
+		}
+		s.Update(now, &events)
+		be.Equal(t, s.Score(), round)
+		be.Equal(t, s.Over(), false)
+	}
+}
+
+func TestSimon_WrongPad(t *testing.T) {
+	s, snd := newTestSimon(t, SimonConfig{Difficulty: SimonHard, Step: 500 * time.Millisecond, InputTimeout: time.Second})
+	events := rbuf.New[Event](8)
+
+	now := runUntilInput(s, time.Unix(0, 0), &events)
+	x, y := keyOf(s, (s.sequence[0]+1)%16)
+	be.NoError(t, events.Write(NewKeyEvent(x, y, KeyDown)))
+	s.Update(now, &events)
+
+	be.Equal(t, s.Over(), true)
+	be.Equal(t, s.Score(), 0)
+	be.Equal(t, snd.played[len(snd.played)-1], SoundGameOver)
+}
+
+func TestSimon_Timeout(t *testing.T) {
+	s, _ := newTestSimon(t, DefaultSimonConfig())
+	events := rbuf.New[Event](8)
+
+	now := runUntilInput(s, time.Unix(0, 0), &events)
+	s.Update(now.Add(DefaultSimonConfig().InputTimeout), &events)
+	be.Equal(t, s.Over(), true)
+}
+
+func TestSimon_Step(t *testing.T) {
+	s, _ := newTestSimon(t, DefaultSimonConfig())
+	s.sequence = make([]uint8, 100)
+	be.Equal(t, s.step(), DefaultSimonConfig().Step/2)
+}
@@ -4,25 +4,204 @@ import (
 	"time"
 	"trelligo/pkg/draw"
 	"trelligo/pkg/rbuf"
+	"trelligo/pkg/shims/rand"
 )
 
-type EventType uint8
-
+// Whack-a-Mole difficulty, every level moles show up more often and hide sooner
 const (
-	Unknown EventType = iota
-	KeyDown
-	KeyUp
+	moleLives       = 3
+	moleHitsPerLvl  = 8
+	moleStartUp     = 1500 * time.Millisecond
+	moleStartSpawn  = 1200 * time.Millisecond
+	moleMinUp       = 400 * time.Millisecond
+	moleMinSpawn    = 300 * time.Millisecond
+	moleMaxVisible  = 3
+	moleFlashLength = 200 * time.Millisecond
 )
 
-type Event interface {
-	X() uint8
-	Y() uint8
-	Type() EventType
-}
+var (
+	colorMole = draw.RGB{R: 0xC0, G: 0x80, B: 0}
+	colorHit  = draw.RGB{R: 0xFF, G: 0xFF, B: 0xFF}
+	colorMiss = draw.RGB{R: 0xFF, G: 0, B: 0}
+)
 
-type Game interface {
-	Update(now time.Time, events rbuf.RingBuffer[Event])
-	Draw(d draw.Display) error
+type mole struct {
+	up    bool
+	until time.Time
+
+	flash      draw.RGB
+	flashUntil time.Time
 }
+
+// WhackAMole shows moles on random keys that need to be pressed before they hide again. Three escaped moles end the
+// game.
 type WhackAMole struct {
+	rnd   *rand.Rand
+	sound Sound
+
+	moles     [16]mole
+	nextSpawn time.Time
+	started   bool
+
+	score int
+	level int
+	lives int
+	hits  int
+
+	buf draw.Buffer4x4
+}
+
+func NewWhackAMole(r *rand.Rand) *WhackAMole {
+	return &WhackAMole{
+		rnd:   r,
+		level: 1,
+		lives: moleLives,
+	}
+}
+
+// SetSound enables sound effects, see SoundHit etc. Playing is best effort, failures are ignored.
+func (w *WhackAMole) SetSound(s Sound) {
+	w.sound = s
+}
+
+func (w *WhackAMole) Score() int {
+	return w.score
+}
+
+func (w *WhackAMole) Level() int {
+	return w.level
+}
+
+func (w *WhackAMole) Over() bool {
+	return w.lives <= 0
+}
+
+func (w *WhackAMole) Update(now time.Time, events *rbuf.RingBuffer[Event]) {
+	if !w.started {
+		w.started = true
+		w.nextSpawn = now.Add(w.spawnEvery())
+	}
+
+	for {
+		e, err := events.Read()
+		if err != nil {
+			break
+		}
+		if e.Type() == KeyDown {
+			w.whack(e.X(), e.Y(), now)
+		}
+	}
+
+	for i := range w.moles {
+		m := &w.moles[i]
+		if !now.Before(m.flashUntil) {
+			m.flash = draw.RGB{}
+		}
+		if m.up && !now.Before(m.until) {
+			m.up = false
+			w.flash(m, colorMiss, now)
+			w.lives--
+			if w.Over() {
+				w.play(SoundGameOver)
+				return
+			}
+			w.play(SoundMiss)
+		}
+	}
+
+	if !now.Before(w.nextSpawn) {
+		w.spawn(now)
+		w.nextSpawn = now.Add(w.spawnEvery())
+	}
+}
+
+func (w *WhackAMole) whack(x, y uint8, now time.Time) {
+	m := &w.moles[4*x+y]
+	if !m.up {
+		return
+	}
+	m.up = false
+	w.flash(m, colorHit, now)
+	w.score += w.level
+	w.hits++
+	if w.hits%moleHitsPerLvl == 0 {
+		w.level++
+		w.play(SoundLevelUp)
+		return
+	}
+	w.play(SoundHit)
+}
+
+// spawn raises a mole on a random free key, unless the maximum for the level is reached
+func (w *WhackAMole) spawn(now time.Time) {
+	visible := 0
+	var free []int
+	for i, m := range w.moles {
+		if m.up {
+			visible++
+		} else if !now.Before(m.flashUntil) {
+			free = append(free, i)
+		}
+	}
+	if visible >= w.maxVisible() || len(free) == 0 {
+		return
+	}
+
+	m := &w.moles[free[w.rnd.Intn(len(free))]]
+	m.up = true
+	m.until = now.Add(w.upFor())
+}
+
+func (w *WhackAMole) flash(m *mole, c draw.RGB, now time.Time) {
+	m.flash = c
+	m.flashUntil = now.Add(moleFlashLength)
+}
+
+// upFor shrinks by 1/8 per level
+func (w *WhackAMole) upFor() time.Duration {
+	return shrink(moleStartUp, moleMinUp, w.level)
+}
+
+func (w *WhackAMole) spawnEvery() time.Duration {
+	return shrink(moleStartSpawn, moleMinSpawn, w.level)
+}
+
+func (w *WhackAMole) maxVisible() int {
+	n := 1 + (w.level-1)/2
+	if n > moleMaxVisible {
+		return moleMaxVisible
+	}
+	return n
+}
+
+func shrink(start, min time.Duration, level int) time.Duration {
+	d := start
+	for i := 1; i < level && d > min; i++ {
+		d -= d / 8
+	}
+	if d < min {
+		return min
+	}
+	return d
+}
+
+func (w *WhackAMole) play(file uint16) {
+	if w.sound == nil {
+		return
+	}
+	_ = w.sound.Advertise(file)
+}
+
+func (w *WhackAMole) Draw(d draw.Display) error {
+	for i, m := range w.moles {
+		switch {
+		case m.up:
+			w.buf[i] = colorMole
+		case m.flash != draw.RGB{}:
+			w.buf[i] = m.flash
+		default:
+			w.buf[i] = draw.RGB{}
+		}
+	}
+	return d.WriteBuffer(&w.buf)
 }
@@ -0,0 +1,114 @@
+package minigames
+
+import (
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/rbuf"
+	"trelligo/pkg/seesaw/keypad"
+	"trelligo/pkg/shims/rand"
+)
+
+type sounds struct {
+	played []uint16
+}
+
+func (s *sounds) Advertise(file uint16) error {
+	s.played = append(s.played, file)
+	return nil
+}
+
+func (w *WhackAMole) visibleMole() (uint8, uint8, bool) {
+	for i, m := range w.moles {
+		if m.up {
+			return uint8(i / 4), uint8(i % 4), true
+		}
+	}
+	return 0, 0, false
+}
+
+func TestWhackAMole_Hit(t *testing.T) {
+	w := NewWhackAMole(rand.New(rand.NewSource(1)))
+	s := &sounds{}
+	w.SetSound(s)
+	events := rbuf.New[Event](8)
+
+	now := time.Unix(0, 0)
+	w.Update(now, &events)
+	now = now.Add(moleStartSpawn)
+	w.Update(now, &events)
+
+	x, y, ok := w.visibleMole()
+	be.Equal(t, ok, true)
+
+	var c draw.Capture
+	be.NoError(t, w.Draw(&c))
+	be.Equal(t, c.Buf[4*x+y], colorMole)
+
+	be.NoError(t, events.Write(NewKeyEvent(x, y, KeyDown)))
+	w.Update(now.Add(time.Millisecond), &events)
+	be.Equal(t, w.Score(), 1)
+	be.Equal(t, s.played[0], SoundHit)
+
+	be.NoError(t, w.Draw(&c))
+	be.Equal(t, c.Buf[4*x+y], colorHit)
+}
+
+func TestWhackAMole_GameOver(t *testing.T) {
+	w := NewWhackAMole(rand.New(rand.NewSource(1)))
+	s := &sounds{}
+	w.SetSound(s)
+	events := rbuf.New[Event](8)
+
+	now := time.Unix(0, 0)
+	for i := 0; i < 100 && !w.Over(); i++ {
+		w.Update(now, &events)
+		now = now.Add(100 * time.Millisecond)
+	}
+	be.Equal(t, w.Over(), true)
+	be.Equal(t, w.Score(), 0)
+	be.Equal(t, s.played[len(s.played)-1], SoundGameOver)
+}
+
+func TestWhackAMole_Levels(t *testing.T) {
+	w := NewWhackAMole(rand.New(rand.NewSource(1)))
+	be.Equal(t, w.upFor(), moleStartUp)
+
+	w.level = 3
+	be.Equal(t, w.upFor() < moleStartUp, true)
+	be.Equal(t, w.maxVisible(), 2)
+
+	w.level = 100
+	be.Equal(t, w.upFor(), moleMinUp)
+	be.Equal(t, w.spawnEvery(), moleMinSpawn)
+	be.Equal(t, w.maxVisible(), moleMaxVisible)
+}
+
+func TestRunner(t *testing.T) {
+	w := NewWhackAMole(rand.New(rand.NewSource(1)))
+	r := NewRunner(w, &draw.Capture{})
+
+	now := time.Unix(0, 0)
+	_, err := r.Step(now)
+	be.NoError(t, err)
+
+	now = now.Add(moleStartSpawn)
+	_, err = r.Step(now)
+	be.NoError(t, err)
+	x, y, _ := w.visibleMole()
+	be.NoError(t, r.HandleKey(x, y, keypad.EdgeRising))
+	be.NoError(t, r.HandleKey(x, y, keypad.EdgeFalling))
+	_, err = r.Step(now)
+	be.NoError(t, err)
+	be.Equal(t, w.Score(), 1)
+
+	done := false
+	for i := 0; i < 1000 && !done; i++ {
+		now = now.Add(100 * time.Millisecond)
+		done, err = r.Step(now)
+		be.NoError(t, err)
+	}
+	be.Equal(t, w.Over(), true)
+	be.Equal(t, done, true)
+}
@@ -6,30 +6,41 @@ import (
 )
 
 type Display struct {
-	dev *neotrellis.Device
-	buf []neotrellis.RGB
+	dev     *neotrellis.Device
+	limiter *draw.Limiter
+	frame   draw.Buffer4x4
+	buf     []neotrellis.RGB
 }
 
+// NewDisplay creates a display that keeps frames within draw.DefaultBudget
 func NewDisplay(dev *neotrellis.Device) *Display {
 	buf := make([]neotrellis.RGB, 16)
 	return &Display{
-		dev: dev,
-		buf: buf,
+		dev:     dev,
+		limiter: draw.NewLimiter(draw.DefaultBudget),
+		buf:     buf,
 	}
 }
 
+// SetLimiter replaces the limiter, e.g. to share one global brightness between displays
+func (n *Display) SetLimiter(l *draw.Limiter) {
+	n.limiter = l
+}
+
+func (n *Display) Limiter() *draw.Limiter {
+	return n.limiter
+}
+
 func (n *Display) WriteBuffer(b *draw.Buffer4x4) error {
 
 	for i := 0; i < len(b); i++ {
-		c := b[i]
-		c = draw.GammaCorrect(c)
-		n.buf[i] = neotrellis.RGB{R: c.R, G: c.G, B: c.B}
+		n.frame[i] = draw.GammaCorrect(b[i])
 	}
+	n.limiter.Limit(&n.frame)
 
-	err := n.dev.WriteColors(n.buf)
-	if err != nil {
-		return err
+	for i, c := range n.frame {
+		n.buf[i] = neotrellis.RGB{R: c.R, G: c.G, B: c.B}
 	}
 
-	return n.dev.ShowPixels()
+	return n.dev.Render(n.buf)
 }
@@ -0,0 +1,52 @@
+package ntdisplay
+
+import (
+	"trelligo/pkg/draw"
+	"trelligo/pkg/neotrellis"
+)
+
+// MultiDisplay shows frames spanning several tiled boards
+type MultiDisplay struct {
+	multi   *neotrellis.MultiTrellis
+	limiter *draw.Limiter
+	frame   *draw.Frame
+	buf     []neotrellis.RGB
+}
+
+// NewMultiDisplay creates a display that keeps frames within draw.DefaultBudget per board
+func NewMultiDisplay(m *neotrellis.MultiTrellis) *MultiDisplay {
+	boards := int(m.Width()) * int(m.Height()) / 16
+	return &MultiDisplay{
+		multi:   m,
+		limiter: draw.NewLimiter(boards * draw.DefaultBudget),
+		frame:   draw.NewFrame(m.Width(), m.Height()),
+		buf:     make([]neotrellis.RGB, int(m.Width())*int(m.Height())),
+	}
+}
+
+func (n *MultiDisplay) SetLimiter(l *draw.Limiter) {
+	n.limiter = l
+}
+
+func (n *MultiDisplay) Limiter() *draw.Limiter {
+	return n.limiter
+}
+
+// WriteFrame shows the frame, parts outside the boards are cut off
+func (n *MultiDisplay) WriteFrame(f *draw.Frame) error {
+	for x := uint8(0); x < n.frame.Width; x++ {
+		for y := uint8(0); y < n.frame.Height; y++ {
+			c := draw.RGB{}
+			if x < f.Width && y < f.Height {
+				c = draw.GammaCorrect(f.At(x, y))
+			}
+			n.frame.Set(x, y, c)
+		}
+	}
+	n.limiter.LimitFrame(n.frame)
+
+	for i, c := range n.frame.Pix {
+		n.buf[i] = neotrellis.RGB{R: c.R, G: c.G, B: c.B}
+	}
+	return n.multi.Render(n.buf)
+}
@@ -0,0 +1,62 @@
+package preview
+
+import (
+	"bytes"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/draw/animations"
+)
+
+func TestTerminal(t *testing.T) {
+	var out bytes.Buffer
+	term := NewTerminal(&out)
+
+	var b draw.Buffer4x4
+	b.Set(0, 3, draw.RGB{R: 1, G: 2, B: 3})
+	be.NoError(t, term.WriteBuffer(&b))
+
+	lines := strings.Split(out.String(), "\n")
+	be.Equal(t, strings.HasPrefix(lines[0], "\x1b[48;2;1;2;3m  "), true)
+
+	out.Reset()
+	be.NoError(t, term.WriteBuffer(&b))
+	be.Equal(t, strings.HasPrefix(out.String(), "\x1b[4A"), true)
+}
+
+func TestRecorder(t *testing.T) {
+	r, err := Record(animations.NewMarquee("1", draw.RGB{R: 0xFF}), 2*time.Second, 20)
+	be.NoError(t, err)
+	be.Equal(t, len(r.Frames), 8)
+	be.Equal(t, r.Frames[0].Duration, animations.DefaultMarqueeSpeed)
+
+	var buf bytes.Buffer
+	be.NoError(t, r.WritePNG(&buf, 2))
+	img, err := png.Decode(&buf)
+	be.NoError(t, err)
+	be.Equal(t, img.Bounds().Dx(), 8*10-2)
+	be.Equal(t, img.Bounds().Dy(), 8)
+
+	buf.Reset()
+	be.NoError(t, r.WriteGIF(&buf, 2))
+	g, err := gif.DecodeAll(&buf)
+	be.NoError(t, err)
+	be.Equal(t, len(g.Image), 8)
+	be.Equal(t, g.Delay[0], 15)
+}
+
+func TestRecorder_Invalid(t *testing.T) {
+	_, err := Record(animations.NewMarquee("1", draw.RGB{R: 0xFF}), time.Second, 0)
+	be.AnError(t, err)
+
+	// nothing is drawn if the duration is shorter than one frame
+	r, err := Record(animations.NewMarquee("1", draw.RGB{R: 0xFF}), 0, 20)
+	be.NoError(t, err)
+	var buf bytes.Buffer
+	be.Equal(t, r.WritePNG(&buf, 2), ErrNoFrames)
+	be.Equal(t, r.WriteGIF(&buf, 2), ErrNoFrames)
+}
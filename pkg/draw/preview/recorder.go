@@ -0,0 +1,124 @@
+package preview
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"strconv"
+	"time"
+	"trelligo/pkg/draw"
+)
+
+// Frame is a recorded frame and how long it was shown
+type Frame struct {
+	Buf      draw.Buffer4x4
+	Duration time.Duration
+}
+
+// Recorder is a draw.Display that keeps all frames, e.g. to export them as GIF or PNG
+type Recorder struct {
+	Frames []Frame
+}
+
+// ErrNoFrames is returned when exporting a recording without frames
+var ErrNoFrames = errors.New("no frames recorded")
+
+func (r *Recorder) WriteBuffer(b *draw.Buffer4x4) error {
+	r.Frames = append(r.Frames, Frame{Buf: *b})
+	return nil
+}
+
+// Record runs the animation for the given duration on a simulated clock and records every frame that changed
+func Record(a draw.Animation, duration time.Duration, fps int) (*Recorder, error) {
+	if fps <= 0 {
+		return nil, errors.New("invalid fps: " + strconv.Itoa(fps))
+	}
+	r := &Recorder{}
+	interval := time.Second / time.Duration(fps)
+	start := time.Unix(0, 0)
+	for t := time.Duration(0); t < duration; t += interval {
+		if a.Update(start.Add(t)) {
+			err := a.Draw(r)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(r.Frames) > 0 {
+			r.Frames[len(r.Frames)-1].Duration += interval
+		}
+	}
+	return r, nil
+}
+
+// WriteGIF writes the frames as animated GIF, each key is scale x scale pixels
+func (r *Recorder) WriteGIF(w io.Writer, scale int) error {
+	if len(r.Frames) == 0 {
+		return ErrNoFrames
+	}
+	anim := &gif.GIF{}
+	for _, f := range r.Frames {
+		anim.Image = append(anim.Image, paletted(&f.Buf, scale))
+		// GIF delays are in 1/100s
+		anim.Delay = append(anim.Delay, int(f.Duration/(10*time.Millisecond)))
+	}
+	return gif.EncodeAll(w, anim)
+}
+
+// WritePNG writes the frames as sprite sheet, from left to right with a gap of one key
+func (r *Recorder) WritePNG(w io.Writer, scale int) error {
+	if len(r.Frames) == 0 {
+		return ErrNoFrames
+	}
+	step := 5 * scale
+	img := image.NewRGBA(image.Rect(0, 0, step*len(r.Frames)-scale, 4*scale))
+	for i, f := range r.Frames {
+		drawBuffer(img, &f.Buf, i*step, scale)
+	}
+	return png.Encode(w, img)
+}
+
+type setter interface {
+	Set(x, y int, c color.Color)
+}
+
+// drawBuffer draws the keys with y=0 at the bottom like on the board
+func drawBuffer(img setter, b *draw.Buffer4x4, left, scale int) {
+	for x := 0; x < 4; x++ {
+		for y := 0; y < 4; y++ {
+			c := b[4*x+y]
+			rgba := color.RGBA{R: c.R, G: c.G, B: c.B, A: 0xFF}
+			top := (3 - y) * scale
+			for dx := 0; dx < scale; dx++ {
+				for dy := 0; dy < scale; dy++ {
+					img.Set(left+x*scale+dx, top+dy, rgba)
+				}
+			}
+		}
+	}
+}
+
+// paletted converts the frame to a paletted image, 16 keys never need more than the 256 GIF colors
+func paletted(b *draw.Buffer4x4, scale int) *image.Paletted {
+	var palette color.Palette
+	for _, c := range b {
+		rgba := color.RGBA{R: c.R, G: c.G, B: c.B, A: 0xFF}
+		if !hasColor(palette, rgba) {
+			palette = append(palette, rgba)
+		}
+	}
+	img := image.NewPaletted(image.Rect(0, 0, 4*scale, 4*scale), palette)
+	drawBuffer(img, b, 0, scale)
+	return img
+}
+
+func hasColor(p color.Palette, c color.RGBA) bool {
+	for _, pc := range p {
+		if pc == c {
+			return true
+		}
+	}
+	return false
+}
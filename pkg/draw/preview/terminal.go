@@ -0,0 +1,43 @@
+// Package preview renders animations on the host, e.g. to review them without flashing a board
+package preview
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"trelligo/pkg/draw"
+)
+
+// Terminal is a draw.Display that renders frames as 24-bit ANSI color blocks. Each frame overwrites the previous one.
+type Terminal struct {
+	w      *bufio.Writer
+	drawn  bool
+	frames int
+}
+
+func NewTerminal(w io.Writer) *Terminal {
+	return &Terminal{w: bufio.NewWriter(w)}
+}
+
+func (t *Terminal) WriteBuffer(b *draw.Buffer4x4) error {
+	if t.drawn {
+		// move the cursor back up to the first row
+		fmt.Fprint(t.w, "\x1b[4A")
+	}
+	t.drawn = true
+	t.frames++
+
+	for y := 3; y >= 0; y-- {
+		for x := 0; x < 4; x++ {
+			c := b[4*x+y]
+			fmt.Fprintf(t.w, "\x1b[48;2;%d;%d;%dm  ", c.R, c.G, c.B)
+		}
+		fmt.Fprint(t.w, "\x1b[0m\n")
+	}
+	return t.w.Flush()
+}
+
+// Frames returns the number of frames rendered
+func (t *Terminal) Frames() int {
+	return t.frames
+}
@@ -0,0 +1,102 @@
+// Package gonybox wires the player to the peripherals of the jukebox. The firmware and the simulator share it, so
+// both run the same player.
+package gonybox
+
+import (
+	"time"
+	"trelligo/pkg/debug"
+	"trelligo/pkg/dfplayer"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/draw/animations"
+	"trelligo/pkg/draw/minigames"
+	"trelligo/pkg/neotrellis"
+	"trelligo/pkg/player"
+	"trelligo/pkg/seesaw/eeprom"
+	"trelligo/pkg/settings"
+	"trelligo/pkg/shims/rand"
+)
+
+// Config are the peripherals of the jukebox
+type Config struct {
+	Trellis  *neotrellis.Device
+	DFPlayer *dfplayer.Player
+	// Knob is the volume potentiometer
+	Knob player.VolumeGetter
+	// Busy is the BUSY pin of the DFPlayer, without it the state is queried
+	Busy dfplayer.Pin
+	Rand *rand.Rand
+	// Now is the clock of the player, time.Now if nil
+	Now func() time.Time
+}
+
+// NewPlayer creates the player with the layout and volume limits stored on the NeoTrellis, the idle screen,
+// the visualizer and the games
+func NewPlayer(cfg Config) (*player.Player, error) {
+	store, s := loadSettings(cfg.Trellis)
+	layout, err := player.LayoutFromSettings(s)
+	if err != nil {
+		debug.Log("warn: invalid layout: " + err.Error())
+		layout = player.DefaultLayout()
+	}
+	volume, err := player.VolumeSettingsFromSettings(s)
+	if err != nil {
+		debug.Log("warn: invalid volume settings: " + err.Error())
+		volume = player.DefaultVolumeSettings()
+	}
+
+	p, err := player.NewWithLayout(cfg.Trellis, cfg.DFPlayer, cfg.Knob, layout)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Now != nil {
+		p.SetClock(cfg.Now)
+	}
+	p.SetVolumePolicy(volume)
+	p.SetRand(cfg.Rand)
+	if store != nil {
+		p.SetSettingsStore(store)
+	}
+	p.SetIdleScreen(animations.NewLife(cfg.Rand))
+
+	var busy *dfplayer.Busy
+	if cfg.Busy != nil {
+		busy = dfplayer.NewBusy(cfg.Busy)
+	}
+	p.SetVisualizer(animations.NewVisualizer(animations.NewPulse(draw.RGB{R: 0x20, G: 0x20, B: 0x40}, draw.MaskAll, 4*time.Second)), busy)
+
+	// alternate between the games, sound effects are advertisements and only play while music is playing
+	games := 0
+	p.SetGame(func() minigames.Game {
+		games++
+		if games%2 == 0 {
+			s := minigames.NewSimon(cfg.Rand, minigames.DefaultSimonConfig())
+			s.SetSound(cfg.DFPlayer)
+			return s
+		}
+		w := minigames.NewWhackAMole(cfg.Rand)
+		w.SetSound(cfg.DFPlayer)
+		return w
+	})
+	return p, nil
+}
+
+// loadSettings loads the settings from the EEPROM of the NeoTrellis, falling back to the defaults.
+// The store is nil if there is no EEPROM.
+func loadSettings(nt *neotrellis.Device) (*settings.Store, settings.Settings) {
+	layout := player.DefaultLayout()
+	defaults := layout.Settings()
+	defaults.Volume = player.DefaultVolumeSettings().Settings()
+
+	e, err := eeprom.New(nt.Seesaw())
+	if err != nil {
+		debug.Log("warn: no eeprom: " + err.Error())
+		return nil, defaults
+	}
+	store := settings.NewStore(e)
+	s, err := store.Load()
+	if err != nil {
+		debug.Log("using default settings: " + err.Error())
+		return store, defaults
+	}
+	return store, s
+}
@@ -0,0 +1,101 @@
+package gonybox
+
+import (
+	"io"
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/debug"
+	"trelligo/pkg/dfplayer"
+	"trelligo/pkg/dfplayer/dfplayersim"
+	"trelligo/pkg/neotrellis"
+	"trelligo/pkg/player"
+	"trelligo/pkg/seesaw/eeprom"
+	"trelligo/pkg/seesaw/seesawsim"
+	"trelligo/pkg/settings"
+	"trelligo/pkg/shims/rand"
+)
+
+type knob struct{}
+
+func (knob) Get() (int, bool) {
+	return 10, true
+}
+
+func TestNewPlayer_Settings(t *testing.T) {
+	debug.SetOutput(io.Discard)
+	now := time.Unix(0, 0)
+	clock := func() time.Time { return now }
+	nt, err := neotrellis.New(seesawsim.New(neotrellis.DefaultNeoTrellisAddress), 0)
+	be.NoError(t, err)
+
+	// a layout saved earlier, with a night maximum of 8
+	layout := player.DefaultLayout()
+	layout.Set(0, 3, player.Key{Action: player.ActionPlayFolder, Folder: 42, Color: player.ColorRed})
+	volume := player.DefaultVolumeSettings()
+	volume.Night.Max = 8
+	s := layout.Settings()
+	s.Volume = volume.Settings()
+	e, err := eeprom.New(nt.Seesaw())
+	be.NoError(t, err)
+	be.NoError(t, settings.NewStore(e).Save(s))
+
+	p, err := NewPlayer(Config{
+		Trellis:  nt,
+		DFPlayer: dfplayer.NewPlayer(dfplayersim.New(clock)),
+		Knob:     knob{},
+		Rand:     rand.New(rand.NewSource(1)),
+		Now:      clock,
+	})
+	be.NoError(t, err)
+	be.Equal(t, p.Layout(), layout)
+	be.Equal(t, p.VolumePolicy().Settings(), volume)
+}
+
+// lossyUART drops every frame the DFPlayer sends on its own, like a UART that clears its buffer before commands
+type lossyUART struct {
+	sim *dfplayersim.Device
+}
+
+func (u lossyUART) Send(tx *dfplayer.Frame, rx *dfplayer.Frame) error {
+	var f dfplayer.Frame
+	for {
+		ok, _ := u.sim.Receive(&f)
+		if !ok {
+			break
+		}
+	}
+	return u.sim.Send(tx, rx)
+}
+
+func TestNewPlayer_LostEvents(t *testing.T) {
+	debug.SetOutput(io.Discard)
+	now := time.Unix(0, 0)
+	clock := func() time.Time { return now }
+	nt, err := neotrellis.New(seesawsim.New(neotrellis.DefaultNeoTrellisAddress), 0)
+	be.NoError(t, err)
+	sim := dfplayersim.New(clock)
+	sim.AddFolder(1, 3)
+	sim.SetTrackLength(time.Minute)
+
+	p, err := NewPlayer(Config{
+		Trellis:  nt,
+		DFPlayer: dfplayer.NewPlayer(lossyUART{sim: sim}),
+		Knob:     knob{},
+		Busy:     sim.BusyPin(),
+		Rand:     rand.New(rand.NewSource(1)),
+		Now:      clock,
+	})
+	be.NoError(t, err)
+	be.NoError(t, p.PlayFolder(1))
+	be.NoError(t, p.Process())
+
+	// the finished event never arrives, the folder moves on once BUSY stayed high for a while
+	for i := 0; i < 4; i++ {
+		now = now.Add(time.Minute / 2)
+		be.NoError(t, p.Process())
+	}
+	_, file := sim.Track()
+	be.Equal(t, file, 2)
+	be.Equal(t, sim.State(), dfplayer.StatePlaying)
+}
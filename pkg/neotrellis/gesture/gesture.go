@@ -0,0 +1,241 @@
+// Package gesture turns raw NeoTrellis key edges into gestures such as taps, long-presses, double-taps and
+// multi-key chords.
+package gesture
+
+import (
+	"time"
+	"trelligo/pkg/neotrellis"
+	"trelligo/pkg/seesaw/keypad"
+)
+
+type Type uint8
+
+const (
+	// Press is emitted as soon as a key goes down
+	Press Type = iota
+	// Release is emitted as soon as a key goes up
+	Release
+	// Tap is emitted when a key is released before it was long-pressed
+	Tap
+	// DoubleTap is emitted instead of a Tap if the same key was tapped shortly before
+	DoubleTap
+	// LongPress is emitted once a key is held for Config.LongPressAfter
+	LongPress
+	// Repeat is emitted every Config.RepeatEvery while a long-pressed key is held
+	Repeat
+	// Chord is emitted once all keys of a chord are held for the hold time of the chord
+	Chord
+)
+
+// Event is a recognized gesture. For Chord events, X and Y are the position of the first key of the chord.
+type Event struct {
+	Type  Type
+	X, Y  uint8
+	Chord int
+}
+
+type Config struct {
+	LongPressAfter  time.Duration
+	RepeatEvery     time.Duration
+	DoubleTapWithin time.Duration
+}
+
+// DefaultConfig has timings that work well for small kids as well as impatient parents
+func DefaultConfig() Config {
+	return Config{
+		LongPressAfter:  800 * time.Millisecond,
+		RepeatEvery:     250 * time.Millisecond,
+		DoubleTapWithin: 300 * time.Millisecond,
+	}
+}
+
+type heldKey struct {
+	pos         neotrellis.Position
+	since       time.Time
+	nextRepeat  time.Time
+	longPressed bool
+	chording    bool
+}
+
+type chord struct {
+	keys  []neotrellis.Position
+	hold  time.Duration
+	fired bool
+}
+
+// Recognizer recognizes gestures from key edges. Edges MUST be fed via HandleKey and timers MUST be advanced via
+// Update for long-presses, repeats and chords to be recognized.
+type Recognizer struct {
+	cfg     Config
+	held    []heldKey
+	chords  []chord
+	handler func(e Event) error
+
+	lastTap   neotrellis.Position
+	lastTapAt time.Time
+	hasTapped bool
+}
+
+func New(cfg Config) *Recognizer {
+	return &Recognizer{cfg: cfg}
+}
+
+// SetHandleFunc sets the callback for recognized gestures
+func (r *Recognizer) SetHandleFunc(handler func(e Event) error) {
+	r.handler = handler
+}
+
+// AddChord registers keys that must be held together for the given time. Keys that are part of a chord do not
+// produce taps or long-presses while held together. Returns the chord number reported in Chord events.
+func (r *Recognizer) AddChord(hold time.Duration, keys ...neotrellis.Position) int {
+	r.chords = append(r.chords, chord{keys: keys, hold: hold})
+	return len(r.chords) - 1
+}
+
+// HandleKey feeds a key edge, e.g. from neotrellis.Device.SetKeyHandleFunc. Both keypad.EdgeRising and
+// keypad.EdgeFalling MUST be enabled on the keypad.
+func (r *Recognizer) HandleKey(x, y uint8, e keypad.Edge, now time.Time) error {
+	pos := neotrellis.PositionFromXY(x, y)
+	switch e {
+	case keypad.EdgeRising:
+		return r.press(pos, now)
+	case keypad.EdgeFalling:
+		return r.release(pos, now)
+	}
+	return nil
+}
+
+func (r *Recognizer) press(pos neotrellis.Position, now time.Time) error {
+	if r.find(pos) >= 0 {
+		// lost a falling edge, treat as still held
+		return nil
+	}
+
+	k := heldKey{pos: pos, since: now}
+	for i := range r.held {
+		if r.shareChord(pos, r.held[i].pos) {
+			r.held[i].chording = true
+			k.chording = true
+		}
+	}
+	r.held = append(r.held, k)
+	return r.emit(Press, pos, 0)
+}
+
+func (r *Recognizer) release(pos neotrellis.Position, now time.Time) error {
+	i := r.find(pos)
+	if i < 0 {
+		return r.emit(Release, pos, 0)
+	}
+	k := r.held[i]
+	r.held = append(r.held[:i], r.held[i+1:]...)
+
+	for c := range r.chords {
+		if r.chords[c].contains(pos) {
+			r.chords[c].fired = false
+		}
+	}
+
+	err := r.emit(Release, pos, 0)
+	if err != nil || k.longPressed || k.chording {
+		return err
+	}
+
+	if r.hasTapped && r.lastTap == pos && now.Sub(r.lastTapAt) <= r.cfg.DoubleTapWithin {
+		r.hasTapped = false
+		return r.emit(DoubleTap, pos, 0)
+	}
+	r.hasTapped = true
+	r.lastTap = pos
+	r.lastTapAt = now
+	return r.emit(Tap, pos, 0)
+}
+
+// Update advances the timers of held keys, it should be called regularly, e.g. once per main loop iteration
+func (r *Recognizer) Update(now time.Time) error {
+	for i := range r.held {
+		k := &r.held[i]
+		if k.chording {
+			continue
+		}
+		if !k.longPressed && now.Sub(k.since) >= r.cfg.LongPressAfter {
+			k.longPressed = true
+			k.nextRepeat = now.Add(r.cfg.RepeatEvery)
+			err := r.emit(LongPress, k.pos, 0)
+			if err != nil {
+				return err
+			}
+		} else if k.longPressed && r.cfg.RepeatEvery > 0 && !now.Before(k.nextRepeat) {
+			k.nextRepeat = k.nextRepeat.Add(r.cfg.RepeatEvery)
+			err := r.emit(Repeat, k.pos, 0)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for c := range r.chords {
+		ch := &r.chords[c]
+		if ch.fired {
+			continue
+		}
+		since, ok := r.heldSince(ch.keys)
+		if !ok || now.Sub(since) < ch.hold {
+			continue
+		}
+		ch.fired = true
+		err := r.emit(Chord, ch.keys[0], c)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// heldSince returns when the last of the keys went down, ok is false if not all keys are held
+func (r *Recognizer) heldSince(keys []neotrellis.Position) (since time.Time, ok bool) {
+	for _, pos := range keys {
+		i := r.find(pos)
+		if i < 0 {
+			return since, false
+		}
+		if r.held[i].since.After(since) {
+			since = r.held[i].since
+		}
+	}
+	return since, len(keys) > 0
+}
+
+func (r *Recognizer) shareChord(a, b neotrellis.Position) bool {
+	for _, c := range r.chords {
+		if c.contains(a) && c.contains(b) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *chord) contains(pos neotrellis.Position) bool {
+	for _, k := range c.keys {
+		if k == pos {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Recognizer) find(pos neotrellis.Position) int {
+	for i := range r.held {
+		if r.held[i].pos == pos {
+			return i
+		}
+	}
+	return -1
+}
+
+func (r *Recognizer) emit(t Type, pos neotrellis.Position, chord int) error {
+	if r.handler == nil {
+		return nil
+	}
+	return r.handler(Event{Type: t, X: pos.X(), Y: pos.Y(), Chord: chord})
+}
@@ -0,0 +1,123 @@
+package gesture
+
+import (
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/neotrellis"
+	"trelligo/pkg/seesaw/keypad"
+)
+
+type recorder struct {
+	events []Event
+}
+
+func (r *recorder) handle(e Event) error {
+	r.events = append(r.events, e)
+	return nil
+}
+
+func (r *recorder) types() []Type {
+	var types []Type
+	for _, e := range r.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func newTestRecognizer() (*Recognizer, *recorder) {
+	rec := &recorder{}
+	r := New(DefaultConfig())
+	r.SetHandleFunc(rec.handle)
+	return r, rec
+}
+
+func equalTypes(t *testing.T, actual []Type, expected ...Type) {
+	t.Helper()
+	be.Equal(t, len(actual), len(expected))
+	for i := range expected {
+		be.Equal(t, actual[i], expected[i])
+	}
+}
+
+func TestRecognizer_Tap(t *testing.T) {
+	r, rec := newTestRecognizer()
+	now := time.Unix(0, 0)
+
+	be.NoError(t, r.HandleKey(1, 2, keypad.EdgeRising, now))
+	now = now.Add(100 * time.Millisecond)
+	be.NoError(t, r.Update(now))
+	be.NoError(t, r.HandleKey(1, 2, keypad.EdgeFalling, now))
+
+	equalTypes(t, rec.types(), Press, Release, Tap)
+	be.Equal(t, rec.events[2].X, 1)
+	be.Equal(t, rec.events[2].Y, 2)
+}
+
+func TestRecognizer_DoubleTap(t *testing.T) {
+	r, rec := newTestRecognizer()
+	now := time.Unix(0, 0)
+
+	for i := 0; i < 2; i++ {
+		be.NoError(t, r.HandleKey(0, 0, keypad.EdgeRising, now))
+		now = now.Add(50 * time.Millisecond)
+		be.NoError(t, r.HandleKey(0, 0, keypad.EdgeFalling, now))
+		now = now.Add(100 * time.Millisecond)
+	}
+
+	equalTypes(t, rec.types(), Press, Release, Tap, Press, Release, DoubleTap)
+}
+
+func TestRecognizer_LongPress(t *testing.T) {
+	r, rec := newTestRecognizer()
+	now := time.Unix(0, 0)
+
+	be.NoError(t, r.HandleKey(0, 0, keypad.EdgeRising, now))
+	for i := 0; i < 13; i++ {
+		now = now.Add(100 * time.Millisecond)
+		be.NoError(t, r.Update(now))
+	}
+	be.NoError(t, r.HandleKey(0, 0, keypad.EdgeFalling, now))
+
+	// long-press at 800ms, repeats due at 1050ms and 1300ms
+	equalTypes(t, rec.types(), Press, LongPress, Repeat, Repeat, Release)
+}
+
+func TestRecognizer_Chord(t *testing.T) {
+	r, rec := newTestRecognizer()
+	stop := neotrellis.PositionFromXY(2, 0)
+	next := neotrellis.PositionFromXY(1, 0)
+	id := r.AddChord(3*time.Second, stop, next)
+
+	now := time.Unix(0, 0)
+	be.NoError(t, r.HandleKey(2, 0, keypad.EdgeRising, now))
+	now = now.Add(200 * time.Millisecond)
+	be.NoError(t, r.HandleKey(1, 0, keypad.EdgeRising, now))
+
+	for i := 0; i < 40; i++ {
+		now = now.Add(100 * time.Millisecond)
+		be.NoError(t, r.Update(now))
+	}
+	be.NoError(t, r.HandleKey(2, 0, keypad.EdgeFalling, now))
+	be.NoError(t, r.HandleKey(1, 0, keypad.EdgeFalling, now))
+
+	// no taps or long-presses for keys of a chord
+	equalTypes(t, rec.types(), Press, Press, Chord, Release, Release)
+	be.Equal(t, rec.events[2].Chord, id)
+}
+
+func TestRecognizer_ChordReleasedEarly(t *testing.T) {
+	r, rec := newTestRecognizer()
+	r.AddChord(3*time.Second, neotrellis.PositionFromXY(2, 0), neotrellis.PositionFromXY(1, 0))
+
+	now := time.Unix(0, 0)
+	be.NoError(t, r.HandleKey(2, 0, keypad.EdgeRising, now))
+	be.NoError(t, r.HandleKey(1, 0, keypad.EdgeRising, now))
+	now = now.Add(time.Second)
+	be.NoError(t, r.Update(now))
+	be.NoError(t, r.HandleKey(1, 0, keypad.EdgeFalling, now))
+	now = now.Add(3 * time.Second)
+	be.NoError(t, r.Update(now))
+
+	equalTypes(t, rec.types(), Press, Press, Release)
+}
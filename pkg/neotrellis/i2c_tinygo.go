@@ -0,0 +1,11 @@
+//go:build tinygo
+
+package neotrellis
+
+import "machine"
+
+// assert the machine.I2C conforms to our interface
+var _ = I2C(&machine.I2C{})
+
+// assert the machine.Pin conforms to our interface
+var _ = Pin(machine.Pin(0))
@@ -0,0 +1,44 @@
+package neotrellis
+
+import "sync/atomic"
+
+// Interrupt tells whether the seesaw signals pending key events, typically backed by its INT line. It lets
+// ProcessKeyEvents skip polling the keypad over I2C while nothing happened.
+type Interrupt interface {
+	Pending() bool
+}
+
+// Pin represents an input pin. It is notably implemented by the machine.Pin type.
+type Pin interface {
+	Get() bool
+}
+
+// PinInterrupt reads the active-low INT line of the seesaw
+type PinInterrupt struct {
+	pin Pin
+}
+
+// NewPinInterrupt creates an Interrupt from the pin connected to INT. The pin MUST be configured as input with
+// pull-up, the seesaw only pulls the line low.
+func NewPinInterrupt(pin Pin) *PinInterrupt {
+	return &PinInterrupt{pin: pin}
+}
+
+func (p *PinInterrupt) Pending() bool {
+	return !p.pin.Get()
+}
+
+// Latch is an Interrupt set from a pin-change callback, e.g. registered via machine.Pin.SetInterrupt for the
+// falling edge of INT. It is reset once the pending events are processed.
+type Latch struct {
+	pending uint32
+}
+
+// Trigger marks key events as pending, it is safe to call from an interrupt handler
+func (l *Latch) Trigger() {
+	atomic.StoreUint32(&l.pending, 1)
+}
+
+func (l *Latch) Pending() bool {
+	return atomic.SwapUint32(&l.pending, 0) != 0
+}
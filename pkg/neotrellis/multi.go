@@ -0,0 +1,246 @@
+package neotrellis
+
+import (
+	"fmt"
+	"trelligo/pkg/seesaw/keypad"
+)
+
+// Rotation is the clockwise rotation of a board within a MultiTrellis
+type Rotation uint8
+
+const (
+	Rotate0 Rotation = iota
+	Rotate90
+	Rotate180
+	Rotate270
+)
+
+// Tile places a board within a MultiTrellis. X and Y count boards, starting at the bottom left like the key
+// coordinates.
+type Tile struct {
+	Device   *Device
+	X, Y     uint8
+	Rotation Rotation
+}
+
+// MultiTrellis composes multiple NeoTrellis boards into one coordinate space, e.g. a 2x2 grid of boards
+// with 8x8 keys. x/y coordinates start at the bottom left, like on a single board.
+type MultiTrellis struct {
+	tiles      []Tile
+	width      uint8
+	height     uint8
+	buf        []RGB
+	keyHandler func(x, y uint8, edge keypad.Edge) error
+}
+
+// NewMulti composes already initialized boards
+func NewMulti(tiles ...Tile) (*MultiTrellis, error) {
+	if len(tiles) == 0 {
+		return nil, fmt.Errorf("no tiles")
+	}
+
+	m := &MultiTrellis{
+		tiles: tiles,
+		buf:   make([]RGB, keyCount),
+	}
+
+	occupied := make(map[uint16]bool)
+	for i := range m.tiles {
+		t := &m.tiles[i]
+		pos := uint16(t.X)<<8 | uint16(t.Y)
+		if occupied[pos] {
+			return nil, fmt.Errorf("two boards at tile %d/%d", t.X, t.Y)
+		}
+		occupied[pos] = true
+
+		m.width = maxu8(m.width, (t.X+1)*xCount)
+		m.height = maxu8(m.height, (t.Y+1)*yCount)
+		t.Device.SetKeyHandleFunc(m.tileKeyHandler(t))
+	}
+
+	return m, nil
+}
+
+// NewMultiFromAddresses initializes one board per address. The layout lists rows of addresses from the bottom to
+// the top, all boards share the same rotation.
+func NewMultiFromAddresses(bus I2C, layout [][]uint16, rotation Rotation) (*MultiTrellis, error) {
+	var tiles []Tile
+	for y, row := range layout {
+		for x, addr := range row {
+			dev, err := New(bus, addr)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize board at 0x%02X: %w", addr, err)
+			}
+			tiles = append(tiles, Tile{Device: dev, X: uint8(x), Y: uint8(y), Rotation: rotation})
+		}
+	}
+	return NewMulti(tiles...)
+}
+
+// Width returns the number of keys in x direction
+func (m *MultiTrellis) Width() uint8 {
+	return m.width
+}
+
+// Height returns the number of keys in y direction
+func (m *MultiTrellis) Height() uint8 {
+	return m.height
+}
+
+// PixelOffset returns the offset of the pixel at x/y within the colors passed to WriteColors. Like on a single
+// board, pixels are ordered column by column.
+func (m *MultiTrellis) PixelOffset(x, y uint8) int {
+	return int(x)*int(m.height) + int(y)
+}
+
+// SetPixelColor sets the color of a pixel at position x/y
+//
+// Note: ShowPixels MUST be called to actually show the updated color.
+func (m *MultiTrellis) SetPixelColor(x, y uint8, color RGB) error {
+	t, lx, ly, err := m.locate(x, y)
+	if err != nil {
+		return err
+	}
+	return t.Device.SetPixelColor(lx, ly, color)
+}
+
+// WriteColors writes the color for multiple pixels at once, ordered as defined by PixelOffset.
+// Note: ShowPixels MUST be called to actually show the updated colors.
+func (m *MultiTrellis) WriteColors(colors []RGB) error {
+	return m.eachTile(colors, (*Device).WriteColors)
+}
+
+// Render writes and shows the colors of multiple pixels at once, ordered as defined by PixelOffset. Only boards
+// with changed pixels are updated, see Device.Render.
+func (m *MultiTrellis) Render(colors []RGB) error {
+	return m.eachTile(colors, (*Device).Render)
+}
+
+// eachTile splits colors into the buffers of the boards and passes them on
+func (m *MultiTrellis) eachTile(colors []RGB, write func(d *Device, colors []RGB) error) error {
+	if len(colors) > int(m.width)*int(m.height) {
+		return fmt.Errorf("too many colors: %d > %d", len(colors), int(m.width)*int(m.height))
+	}
+
+	for i := range m.tiles {
+		t := &m.tiles[i]
+		for lx := uint8(0); lx < xCount; lx++ {
+			for ly := uint8(0); ly < yCount; ly++ {
+				x, y := t.toGlobal(lx, ly)
+				offset := m.PixelOffset(x, y)
+				c := RGB{}
+				if offset < len(colors) {
+					c = colors[offset]
+				}
+				m.buf[PositionFromXY(lx, ly).PixelOffset()] = c
+			}
+		}
+		err := write(t.Device, m.buf)
+		if err != nil {
+			return fmt.Errorf("failed to write colors of tile %d/%d: %w", t.X, t.Y, err)
+		}
+	}
+	return nil
+}
+
+// ShowPixels instructs all boards to display the set colors
+func (m *MultiTrellis) ShowPixels() error {
+	for _, t := range m.tiles {
+		err := t.Device.ShowPixels()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConfigureKeypad enables or disables a key and edge. Events can be handled by setting a handler
+// with SetKeyHandleFunc.
+func (m *MultiTrellis) ConfigureKeypad(x, y uint8, edge keypad.Edge, enable bool) error {
+	t, lx, ly, err := m.locate(x, y)
+	if err != nil {
+		return err
+	}
+	return t.Device.ConfigureKeypad(lx, ly, edge, enable)
+}
+
+// SetKeyHandleFunc sets a callback for key events, the coordinates span all boards
+//
+// Note: In order for the handler to be called, the keypads MUST be configured via ConfigureKeypad and the events
+// MUST be processed via ProcessKeyEvents.
+func (m *MultiTrellis) SetKeyHandleFunc(handler func(x, y uint8, e keypad.Edge) error) {
+	m.keyHandler = handler
+}
+
+// ProcessKeyEvents reads pending keypad.KeyEvent s of all boards and processes them
+func (m *MultiTrellis) ProcessKeyEvents() error {
+	for _, t := range m.tiles {
+		err := t.Device.ProcessKeyEvents()
+		if err != nil {
+			return fmt.Errorf("tile %d/%d: %w", t.X, t.Y, err)
+		}
+	}
+	return nil
+}
+
+func (m *MultiTrellis) tileKeyHandler(t *Tile) func(x, y uint8, e keypad.Edge) error {
+	return func(lx, ly uint8, e keypad.Edge) error {
+		if m.keyHandler == nil {
+			return nil
+		}
+		x, y := t.toGlobal(lx, ly)
+		return m.keyHandler(x, y, e)
+	}
+}
+
+// locate finds the tile of a global position and the board-local coordinates
+func (m *MultiTrellis) locate(x, y uint8) (*Tile, uint8, uint8, error) {
+	for i := range m.tiles {
+		t := &m.tiles[i]
+		if x/xCount != t.X || y/yCount != t.Y {
+			continue
+		}
+		lx, ly := t.toLocal(x%xCount, y%yCount)
+		return t, lx, ly, nil
+	}
+	return nil, 0, 0, fmt.Errorf("no board at %d/%d", x, y)
+}
+
+// toGlobal maps board-local coordinates to the global coordinate space
+func (t *Tile) toGlobal(lx, ly uint8) (uint8, uint8) {
+	const max = xCount - 1
+	var tx, ty uint8
+	switch t.Rotation {
+	case Rotate90:
+		tx, ty = ly, max-lx
+	case Rotate180:
+		tx, ty = max-lx, max-ly
+	case Rotate270:
+		tx, ty = max-ly, lx
+	default:
+		tx, ty = lx, ly
+	}
+	return t.X*xCount + tx, t.Y*yCount + ty
+}
+
+// toLocal maps coordinates within the tile to board-local coordinates
+func (t *Tile) toLocal(tx, ty uint8) (uint8, uint8) {
+	const max = xCount - 1
+	switch t.Rotation {
+	case Rotate90:
+		return max - ty, tx
+	case Rotate180:
+		return max - tx, max - ty
+	case Rotate270:
+		return ty, max - tx
+	default:
+		return tx, ty
+	}
+}
+
+func maxu8(a, b uint8) uint8 {
+	if a < b {
+		return b
+	}
+	return a
+}
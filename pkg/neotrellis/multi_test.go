@@ -0,0 +1,78 @@
+package neotrellis
+
+import (
+	"testing"
+	"trelligo/pkg/be"
+	"trelligo/pkg/seesaw/keypad"
+	"trelligo/pkg/seesaw/seesawsim"
+)
+
+func TestTile_RoundTrip(t *testing.T) {
+	for r := Rotate0; r <= Rotate270; r++ {
+		tile := Tile{X: 1, Y: 1, Rotation: r}
+		seen := make(map[Position]bool)
+		for lx := uint8(0); lx < xCount; lx++ {
+			for ly := uint8(0); ly < yCount; ly++ {
+				x, y := tile.toGlobal(lx, ly)
+				be.Equal(t, x/xCount, 1)
+				be.Equal(t, y/yCount, 1)
+				seen[PositionFromXY(x, y)] = true
+
+				rx, ry := tile.toLocal(x%xCount, y%yCount)
+				be.Equal(t, rx, lx)
+				be.Equal(t, ry, ly)
+			}
+		}
+		be.Equal(t, len(seen), keyCount)
+	}
+}
+
+func TestTile_Rotate90(t *testing.T) {
+	tile := Tile{Rotation: Rotate90}
+
+	// bottom left ends up top left
+	x, y := tile.toGlobal(0, 0)
+	be.Equal(t, x, 0)
+	be.Equal(t, y, 3)
+
+	// bottom right ends up bottom left
+	x, y = tile.toGlobal(3, 0)
+	be.Equal(t, x, 0)
+	be.Equal(t, y, 0)
+}
+
+func TestMultiTrellis(t *testing.T) {
+	left := seesawsim.New(DefaultNeoTrellisAddress)
+	right := seesawsim.New(DefaultNeoTrellisAddress + 1)
+	bus := seesawsim.Bus{left, right}
+
+	m, err := NewMultiFromAddresses(bus, [][]uint16{{DefaultNeoTrellisAddress, DefaultNeoTrellisAddress + 1}}, Rotate0)
+	be.NoError(t, err)
+	be.Equal(t, m.Width(), 8)
+	be.Equal(t, m.Height(), 4)
+
+	var gotX, gotY uint8
+	m.SetKeyHandleFunc(func(x, y uint8, e keypad.Edge) error {
+		gotX, gotY = x, y
+		return nil
+	})
+	be.NoError(t, m.ConfigureKeypad(5, 2, keypad.EdgeRising, true))
+	be.Equal(t, right.KeyEventEnabled(PositionFromXY(1, 2).KeyID(), uint8(keypad.EdgeRising)), true)
+
+	right.Press(PositionFromXY(1, 2).KeyID())
+	be.NoError(t, m.ProcessKeyEvents())
+	be.Equal(t, gotX, 5)
+	be.Equal(t, gotY, 2)
+
+	colors := make([]RGB, int(m.Width())*int(m.Height()))
+	colors[m.PixelOffset(6, 3)] = RGB{R: 1, G: 2, B: 3}
+	be.NoError(t, m.WriteColors(colors))
+	be.NoError(t, m.ShowPixels())
+
+	shown := right.ShownPixels()
+	offset := PositionFromXY(2, 3).PixelOffset() * 3
+	be.Equal(t, shown[offset], 2)
+	be.Equal(t, shown[offset+1], 1)
+	be.Equal(t, shown[offset+2], 3)
+	be.Equal(t, left.Shows(), 1)
+}
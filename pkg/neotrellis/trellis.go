@@ -25,7 +25,13 @@ type Device struct {
 	pix        *neopixel.Device
 	kpd        *keypad.SeesawKeypad
 	events     []keypad.KeyEvent
+	frame      []neopixel.RGBW
 	keyHandler func(x, y uint8, edge keypad.Edge) error
+
+	interrupt Interrupt
+	// morePending the FIFO held more events than fit the buffer or reading them failed, the interrupt won't fire
+	// again for them
+	morePending bool
 }
 
 func New(dev I2C, addr uint16) (*Device, error) {
@@ -44,7 +50,10 @@ func New(dev I2C, addr uint16) (*Device, error) {
 		return nil, err
 	}
 
-	kbd := keypad.New(ss)
+	kbd, err := keypad.New(ss)
+	if err != nil {
+		return nil, err
+	}
 	err = kbd.SetKeypadInterrupt(true)
 	if err != nil {
 		return nil, err
@@ -55,9 +64,21 @@ func New(dev I2C, addr uint16) (*Device, error) {
 		pix:    pix,
 		kpd:    kbd,
 		events: make([]keypad.KeyEvent, 16),
+		frame:  make([]neopixel.RGBW, keyCount),
 	}, nil
 }
 
+// Seesaw returns the underlying seesaw, e.g. to use its EEPROM
+func (d *Device) Seesaw() *seesaw.Device {
+	return d.dev
+}
+
+// SetAdaptiveTiming enables or disables adaptive read delays of the underlying seesaw, calibrate it first, see
+// seesaw.Device
+func (d *Device) SetAdaptiveTiming(enable bool) {
+	d.dev.SetAdaptiveTiming(enable)
+}
+
 // SetPixelColor sets the color of a pixel at position x/y
 //
 // Note: ShowPixels MUST be called to actually show the updated color.
@@ -89,6 +110,24 @@ func (d *Device) WriteColors(colors []RGB) error {
 	return d.pix.WriteColors(buf)
 }
 
+// Render writes and shows the colors of multiple pixels at once. Only the pixels that changed since the last write
+// are sent, if nothing changed at all neither colors nor a show command are sent.
+func (d *Device) Render(colors []RGB) error {
+	if len(colors) > keyCount {
+		return fmt.Errorf("too many colors: %d > %d", len(colors), keyCount)
+	}
+
+	buf := d.frame[:len(colors)]
+	for i, c := range colors {
+		buf[i] = neopixel.RGBW{
+			R: c.R,
+			G: c.G,
+			B: c.B,
+		}
+	}
+	return d.pix.Render(buf)
+}
+
 // ShowPixels instructs the NeoPixel buffer to update and display the set colors
 func (d *Device) ShowPixels() error {
 	return d.pix.ShowPixels()
@@ -108,23 +147,39 @@ func (d *Device) SetKeyHandleFunc(handler func(x, y uint8, e keypad.Edge) error)
 	d.keyHandler = handler
 }
 
+// SetInterrupt sets a source that signals pending key events, e.g. a PinInterrupt on the pin connected to INT.
+// ProcessKeyEvents then only talks to the seesaw if events are pending. Set to nil to poll on every call.
+func (d *Device) SetInterrupt(i Interrupt) {
+	d.interrupt = i
+	d.morePending = i != nil
+}
+
 // ProcessKeyEvents reads pending keypad.KeyEvent s from the FIFO and processes them
 func (d *Device) ProcessKeyEvents() error {
 
+	if d.interrupt != nil && !d.interrupt.Pending() && !d.morePending {
+		return nil
+	}
+
 	n, err := d.kpd.KeyEventCount()
 	if err != nil {
 		// NOTE: The device seems to respond with a NACK if there are no events, let's ignore them.
 		// This only works with the current machine.I2C implementation.
 		if err.Error() == "I2C error: expected ACK not NACK" {
+			d.morePending = false
 			return nil
 		}
+		// the interrupt was consumed, try again next time
+		d.morePending = d.interrupt != nil
 		return fmt.Errorf("failed to read key event count: %w", err)
 	}
 
 	buf := d.events[:minu8(uint8(cap(d.events)), n)]
+	d.morePending = int(n) > len(buf)
 
 	err = d.kpd.Read(buf)
 	if err != nil {
+		d.morePending = d.interrupt != nil
 		return fmt.Errorf("failed to read key event buffer: %w", err)
 	}
 
@@ -0,0 +1,193 @@
+package neotrellis
+
+import (
+	"errors"
+	"testing"
+	"trelligo/pkg/be"
+	"trelligo/pkg/seesaw/keypad"
+	"trelligo/pkg/seesaw/seesawsim"
+)
+
+func newTestDevice(t *testing.T) (*Device, *seesawsim.Device) {
+	sim := seesawsim.New(DefaultNeoTrellisAddress)
+	nt, err := New(sim, 0)
+	be.NoError(t, err)
+	return nt, sim
+}
+
+func TestNew(t *testing.T) {
+	_, sim := newTestDevice(t)
+
+	be.Equal(t, sim.Resets(), 1)
+	be.Equal(t, sim.KeypadInterruptEnabled(), true)
+	be.Equal(t, sim.PixelPin(), neoPixelPin)
+	be.Equal(t, len(sim.PixelBuffer()), keyCount*3)
+}
+
+func TestDevice_ProcessKeyEvents(t *testing.T) {
+	nt, sim := newTestDevice(t)
+
+	type keyEvent struct {
+		x, y uint8
+		e    keypad.Edge
+	}
+	var events []keyEvent
+	nt.SetKeyHandleFunc(func(x, y uint8, e keypad.Edge) error {
+		events = append(events, keyEvent{x, y, e})
+		return nil
+	})
+
+	// nothing pending, the seesaw NACKs
+	err := nt.ProcessKeyEvents()
+	be.NoError(t, err)
+	be.Equal(t, len(events), 0)
+
+	be.NoError(t, nt.ConfigureKeypad(2, 1, keypad.EdgeRising, true))
+	be.NoError(t, nt.ConfigureKeypad(2, 1, keypad.EdgeFalling, true))
+
+	sim.Press(PositionFromXY(2, 1).KeyID())
+	sim.Release(PositionFromXY(2, 1).KeyID())
+	// not configured
+	sim.Press(PositionFromXY(3, 3).KeyID())
+
+	err = nt.ProcessKeyEvents()
+	be.NoError(t, err)
+	be.Equal(t, len(events), 2)
+	be.Equal(t, events[0], keyEvent{2, 1, keypad.EdgeRising})
+	be.Equal(t, events[1], keyEvent{2, 1, keypad.EdgeFalling})
+}
+
+func TestDevice_SetPixelColor(t *testing.T) {
+	nt, sim := newTestDevice(t)
+
+	be.NoError(t, nt.SetPixelColor(1, 2, RGB{R: 10, G: 20, B: 30}))
+	be.NoError(t, nt.ShowPixels())
+
+	shown := sim.ShownPixels()
+	offset := PositionFromXY(1, 2).PixelOffset() * 3
+	be.Equal(t, shown[offset], 20)
+	be.Equal(t, shown[offset+1], 10)
+	be.Equal(t, shown[offset+2], 30)
+}
+
+func TestPosition_RoundTrip(t *testing.T) {
+	for x := uint8(0); x < xCount; x++ {
+		for y := uint8(0); y < yCount; y++ {
+			p := newXyFromSeesawKey(PositionFromXY(x, y).KeyID())
+			be.Equal(t, p.X(), x)
+			be.Equal(t, p.Y(), y)
+		}
+	}
+}
+
+func TestDevice_ProcessKeyEvents_PinInterrupt(t *testing.T) {
+	nt, sim := newTestDevice(t)
+	nt.SetInterrupt(NewPinInterrupt(sim.IntPin()))
+
+	pressed := 0
+	nt.SetKeyHandleFunc(func(x, y uint8, e keypad.Edge) error {
+		pressed++
+		return nil
+	})
+	be.NoError(t, nt.ConfigureKeypad(0, 0, keypad.EdgeRising, true))
+
+	// drains whatever was pending before the interrupt was set
+	be.NoError(t, nt.ProcessKeyEvents())
+
+	// INT not asserted, no I2C traffic at all
+	before := sim.Transactions()
+	be.NoError(t, nt.ProcessKeyEvents())
+	be.Equal(t, sim.Transactions(), before)
+
+	sim.Press(PositionFromXY(0, 0).KeyID())
+	be.Equal(t, sim.InterruptAsserted(), true)
+	be.NoError(t, nt.ProcessKeyEvents())
+	be.Equal(t, pressed, 1)
+	be.Equal(t, sim.InterruptAsserted(), false)
+}
+
+func TestDevice_ProcessKeyEvents_Latch(t *testing.T) {
+	nt, sim := newTestDevice(t)
+	latch := &Latch{}
+	sim.SetInterruptFunc(latch.Trigger)
+	nt.SetInterrupt(latch)
+
+	pressed := 0
+	nt.SetKeyHandleFunc(func(x, y uint8, e keypad.Edge) error {
+		pressed++
+		return nil
+	})
+	be.NoError(t, nt.ConfigureKeypad(1, 1, keypad.EdgeRising, true))
+	be.NoError(t, nt.ProcessKeyEvents())
+
+	// more events than fit the buffer, but only one interrupt per event
+	for i := 0; i < 20; i++ {
+		sim.Press(PositionFromXY(1, 1).KeyID())
+	}
+	be.NoError(t, nt.ProcessKeyEvents())
+	be.Equal(t, pressed, 16)
+	be.NoError(t, nt.ProcessKeyEvents())
+	be.Equal(t, pressed, 20)
+
+	before := sim.Transactions()
+	be.NoError(t, nt.ProcessKeyEvents())
+	be.NoError(t, nt.ProcessKeyEvents())
+	be.Equal(t, sim.Transactions(), before)
+}
+
+// flakyBus fails the next transfers with an error other than a NACK
+type flakyBus struct {
+	*seesawsim.Device
+	failures int
+}
+
+func (b *flakyBus) Tx(addr uint16, w, r []byte) error {
+	if b.failures > 0 {
+		b.failures--
+		return errors.New("I2C error: timeout")
+	}
+	return b.Device.Tx(addr, w, r)
+}
+
+func TestDevice_ProcessKeyEvents_LatchError(t *testing.T) {
+	sim := seesawsim.New(DefaultNeoTrellisAddress)
+	bus := &flakyBus{Device: sim}
+	nt, err := New(bus, 0)
+	be.NoError(t, err)
+	latch := &Latch{}
+	sim.SetInterruptFunc(latch.Trigger)
+	nt.SetInterrupt(latch)
+
+	pressed := 0
+	nt.SetKeyHandleFunc(func(x, y uint8, e keypad.Edge) error {
+		pressed++
+		return nil
+	})
+	be.NoError(t, nt.ConfigureKeypad(1, 1, keypad.EdgeRising, true))
+	be.NoError(t, nt.ProcessKeyEvents())
+
+	// the latch is cleared before the read fails, the events must not get stranded
+	sim.Press(PositionFromXY(1, 1).KeyID())
+	bus.failures = 1
+	be.AnError(t, nt.ProcessKeyEvents())
+	be.NoError(t, nt.ProcessKeyEvents())
+	be.Equal(t, pressed, 1)
+}
+
+func TestDevice_ProcessKeyEvents_FromInterrupt(t *testing.T) {
+	nt, sim := newTestDevice(t)
+	pressed := 0
+	nt.SetKeyHandleFunc(func(x, y uint8, e keypad.Edge) error {
+		pressed++
+		return nil
+	})
+	be.NoError(t, nt.ConfigureKeypad(1, 1, keypad.EdgeRising, true))
+
+	// the callback reads the device right away, like an interrupt handler that drains the FIFO
+	sim.SetInterruptFunc(func() {
+		be.NoError(t, nt.ProcessKeyEvents())
+	})
+	sim.Press(PositionFromXY(1, 1).KeyID())
+	be.Equal(t, pressed, 1)
+	be.Equal(t, sim.PendingKeyEvents(), 0)
+}
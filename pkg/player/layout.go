@@ -0,0 +1,165 @@
+package player
+
+import (
+	"fmt"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/settings"
+)
+
+// Action is what a key does when tapped
+type Action uint8
+
+const (
+	ActionNone Action = iota
+	// ActionPlayFolder plays the folder of the key, long-pressing plays it from the start
+	ActionPlayFolder
+	ActionNext
+	ActionPrevious
+	ActionStop
+	// ActionPause toggles between pause and play
+	ActionPause
+	ActionVolumeUp
+	ActionVolumeDown
+	// ActionShuffle toggles playing all tracks in random order, see ModeShuffleAll
+	ActionShuffle
+	// ActionLoop toggles repeating the current track, see ModeRepeatOne
+	ActionLoop
+	ActionSleepTimer
+	// ActionMode cycles through the folder modes, the key shows the current one
+	ActionMode
+	actionCount
+)
+
+// Color is an index into the key palette, small enough to be persisted in the settings
+type Color uint8
+
+const (
+	ColorOff Color = iota
+	ColorCyan
+	ColorTeal
+	ColorRed
+	ColorGreen
+	ColorBlue
+	ColorYellow
+	ColorOrange
+	ColorPurple
+	ColorPink
+	ColorWhite
+	colorCount
+)
+
+// palette of key colors before gamma correction
+var palette = [colorCount]draw.RGB{
+	ColorOff:    {},
+	ColorCyan:   {R: 0, G: 183, B: 211},
+	ColorTeal:   {R: 0, G: 211, B: 183},
+	ColorRed:    {R: 0xFF, G: 0, B: 0},
+	ColorGreen:  {R: 0, G: 0xFF, B: 0},
+	ColorBlue:   {R: 0, G: 0, B: 0xFF},
+	ColorYellow: {R: 0xFF, G: 0xC0, B: 0},
+	ColorOrange: {R: 0xFF, G: 0x60, B: 0},
+	ColorPurple: {R: 0x80, G: 0, B: 0xFF},
+	ColorPink:   {R: 0xFF, G: 0x40, B: 0x80},
+	ColorWhite:  {R: 0xFF, G: 0xFF, B: 0xFF},
+}
+
+// RGB returns the color before gamma correction, unknown colors are off
+func (c Color) RGB() draw.RGB {
+	if c >= colorCount {
+		return draw.RGB{}
+	}
+	return palette[c]
+}
+
+// Key is an entry of the Layout, Folder is only used by ActionPlayFolder
+type Key struct {
+	Action Action
+	Folder uint8
+	Color  Color
+}
+
+// Layout maps each key to what it does, indexed by y<<2 | x
+type Layout [16]Key
+
+func (l *Layout) Set(x, y uint8, k Key) {
+	l[newXy(x, y)] = k
+}
+
+func (l *Layout) At(x, y uint8) Key {
+	return l[newXy(x, y)]
+}
+
+// modeColors show the playback mode on the mode key, the normal mode has the color of the key
+var modeColors = [modeCount]Color{
+	ModeRepeatOne:     ColorOrange,
+	ModeRepeatFolder:  ColorGreen,
+	ModeShuffleFolder: ColorBlue,
+	ModeShuffleAll:    ColorPink,
+}
+
+// color returns the color of the key for the playback mode
+func (k Key) color(m PlaybackMode) Color {
+	if k.Action != ActionMode || m == ModeNormal || m >= modeCount {
+		return k.Color
+	}
+	return modeColors[m]
+}
+
+// keyOf returns the key playing the folder, -1 if there is none
+func (l *Layout) keyOf(folder uint8) int {
+	for i, k := range l {
+		if k.Action == ActionPlayFolder && k.Folder == folder {
+			return i
+		}
+	}
+	return -1
+}
+
+// DefaultLayout has 9 folder keys on top, the bottom row controls the playback
+//
+//	[  1  2  3  4 ]
+//	[  5  6  7  8 ]
+//	[  9  .  M  Z ]
+//	[  <  >  x  P ]
+//
+// M cycles through the playback modes, Z starts and cancels the sleep timer.
+func DefaultLayout() Layout {
+	var l Layout
+	for i := 0; i < 9; i++ {
+		l.Set(uint8(i%4), uint8(3-i/4), Key{Action: ActionPlayFolder, Folder: uint8(i + 1), Color: ColorCyan})
+	}
+	l.Set(0, 0, Key{Action: ActionPrevious, Color: ColorCyan})
+	l.Set(1, 0, Key{Action: ActionNext, Color: ColorTeal})
+	l.Set(2, 0, Key{Action: ActionStop, Color: ColorRed})
+	l.Set(3, 0, Key{Action: ActionPause, Color: ColorYellow})
+	l.Set(2, 1, Key{Action: ActionMode, Color: ColorWhite})
+	l.Set(3, 1, Key{Action: ActionSleepTimer, Color: ColorPurple})
+	return l
+}
+
+// LayoutFromSettings validates the persisted keys
+func LayoutFromSettings(s settings.Settings) (Layout, error) {
+	var l Layout
+	for i, k := range s.Keys {
+		if Action(k.Action) >= actionCount {
+			return l, fmt.Errorf("unknown action %d of key %d", k.Action, i)
+		}
+		if Color(k.Color) >= colorCount {
+			return l, fmt.Errorf("unknown color %d of key %d", k.Color, i)
+		}
+		if Action(k.Action) == ActionPlayFolder && (k.Arg == 0 || k.Arg > maxFolders) {
+			return l, fmt.Errorf("invalid folder %d of key %d", k.Arg, i)
+		}
+		l[i] = Key{Action: Action(k.Action), Folder: k.Arg, Color: Color(k.Color)}
+	}
+	return l, nil
+}
+
+// Settings returns the persisted form of the layout
+func (l *Layout) Settings() settings.Settings {
+	var s settings.Settings
+	for i, k := range l {
+		s.Keys[i] = settings.Key{Action: uint8(k.Action), Arg: k.Folder, Color: uint8(k.Color)}
+	}
+	return s
+}
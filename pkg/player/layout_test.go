@@ -0,0 +1,43 @@
+package player
+
+import (
+	"testing"
+	"trelligo/pkg/be"
+	"trelligo/pkg/settings"
+)
+
+func TestLayout_Settings(t *testing.T) {
+	l := DefaultLayout()
+	be.Equal(t, l.At(0, 3), Key{Action: ActionPlayFolder, Folder: 1, Color: ColorCyan})
+	be.Equal(t, l.At(0, 1), Key{Action: ActionPlayFolder, Folder: 9, Color: ColorCyan})
+	be.Equal(t, l.At(3, 0).Action, ActionPause)
+
+	got, err := LayoutFromSettings(l.Settings())
+	be.NoError(t, err)
+	be.Equal(t, got, l)
+
+	var s settings.Settings
+	s.Keys[2].Action = uint8(actionCount)
+	_, err = LayoutFromSettings(s)
+	be.AnError(t, err)
+
+	// the DFPlayer only has the folders 01 to 99
+	s.Keys[2] = settings.Key{Action: uint8(ActionPlayFolder), Arg: 100}
+	_, err = LayoutFromSettings(s)
+	be.AnError(t, err)
+	s.Keys[2].Arg = 0
+	_, err = LayoutFromSettings(s)
+	be.AnError(t, err)
+	s.Keys[2].Arg = 99
+	_, err = LayoutFromSettings(s)
+	be.NoError(t, err)
+}
+
+func TestKey_color(t *testing.T) {
+	l := DefaultLayout()
+	k := l.At(2, 1)
+	be.Equal(t, k.Action, ActionMode)
+	be.Equal(t, k.color(ModeNormal), ColorWhite)
+	be.Equal(t, k.color(ModeShuffleFolder), ColorBlue)
+	be.Equal(t, l.At(0, 3).color(ModeShuffleFolder), ColorCyan)
+}
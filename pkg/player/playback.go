@@ -0,0 +1,450 @@
+package player
+
+import (
+	"strconv"
+	"time"
+	"trelligo/pkg/debug"
+	"trelligo/pkg/errwrap"
+	"trelligo/pkg/shims/rand"
+)
+
+// PlaybackState is what the player is doing as far as the Playback knows
+type PlaybackState uint8
+
+const (
+	Stopped PlaybackState = iota
+	Playing
+	Paused
+)
+
+// PlaybackMode is what plays after a track finished
+type PlaybackMode uint8
+
+const (
+	// ModeNormal plays the folder once
+	ModeNormal PlaybackMode = iota
+	// ModeRepeatOne repeats the current track, the DFPlayer loops it on its own
+	ModeRepeatOne
+	// ModeRepeatFolder starts the folder over after the last track. The playback does it rather than the
+	// DFPlayer's folder loop, which would play on without telling the track and lose the resume positions.
+	ModeRepeatFolder
+	// ModeShuffleFolder plays the tracks of the folder in random order
+	ModeShuffleFolder
+	// ModeShuffleAll plays all tracks on the SD card in random order, it interrupts the folder so it isn't part
+	// of the mode cycle
+	ModeShuffleAll
+	modeCount
+)
+
+func (m PlaybackMode) String() string {
+	switch m {
+	case ModeNormal:
+		return "normal"
+	case ModeRepeatOne:
+		return "repeat one"
+	case ModeRepeatFolder:
+		return "repeat folder"
+	case ModeShuffleFolder:
+		return "shuffle folder"
+	case ModeShuffleAll:
+		return "shuffle all"
+	}
+	return "mode " + strconv.Itoa(int(m))
+}
+
+// next returns the mode after m in the cycle of the folder modes
+func (m PlaybackMode) next() PlaybackMode {
+	if m+1 >= ModeShuffleAll {
+		return ModeNormal
+	}
+	return m + 1
+}
+
+// maxFolders the DFPlayer supports the folders 01 to 99
+const maxFolders = 99
+
+// finishedRepeat the DFPlayer reports a finished track twice, the repeat is ignored within this time
+const finishedRepeat = time.Second
+
+// finishedWait how long the finished event of a track may take after the DFPlayer reported it stopped
+const finishedWait = time.Second
+
+// syncGrace how long after a command the state reported by the DFPlayer isn't trusted, it takes a while to start
+const syncGrace = 2 * time.Second
+
+// folderPlayer is the part of the DFPlayer the playback uses
+type folderPlayer interface {
+	PlayFolder(folder uint8, file uint8) error
+	PlayNext() error
+	PlayPrevious() error
+	Pause() error
+	Unpause() error
+	Stop() error
+	SetLoop(enable bool) error
+	RandomAll() error
+	QueryFolderFiles(folder uint8) (uint16, error)
+}
+
+// Playback keeps track of the playing folder and track and where each folder was left, so audiobooks resume
+// at the last chapter.
+type Playback struct {
+	dfp folderPlayer
+	now func() time.Time
+	r   *rand.Rand
+
+	mode  PlaybackMode
+	order []uint8
+	pos   int
+
+	state  PlaybackState
+	folder uint8
+	track  uint8
+
+	resume [maxFolders + 1]uint8
+	files  [maxFolders + 1]uint8
+	// shuffledFrom is the folder that played before all tracks were shuffled
+	shuffledFrom uint8
+
+	commandAt     time.Time
+	stoppedAt     time.Time
+	stopSeen      bool
+	finished      uint16
+	finishedAt    time.Time
+	finishedKnown bool
+}
+
+func NewPlayback(dfp folderPlayer, now func() time.Time) *Playback {
+	return &Playback{dfp: dfp, now: now, r: rand.New(rand.NewSource(1))}
+}
+
+// SetRand sets the source of the folder shuffle
+func (pb *Playback) SetRand(r *rand.Rand) {
+	pb.r = r
+}
+
+func (pb *Playback) Mode() PlaybackMode {
+	return pb.mode
+}
+
+// SetMode changes what plays after a track finished, shuffling all tracks starts right away. Leaving it resumes
+// the folder that played before.
+func (pb *Playback) SetMode(m PlaybackMode) error {
+	if m >= modeCount {
+		m = ModeNormal
+	}
+	prev := pb.mode
+	pb.mode = m
+	debug.Log("playback mode: " + m.String())
+
+	if prev == ModeShuffleAll && m != ModeShuffleAll {
+		err := pb.leaveShuffleAll()
+		if err != nil {
+			return err
+		}
+	}
+	if prev == ModeRepeatOne && m != ModeRepeatOne {
+		err := pb.dfp.SetLoop(false)
+		if err != nil {
+			return err
+		}
+	}
+	switch m {
+	case ModeRepeatOne:
+		if pb.state != Stopped {
+			return pb.dfp.SetLoop(true)
+		}
+	case ModeShuffleFolder:
+		if pb.folder != 0 {
+			pb.shuffle(pb.folder, pb.track)
+		}
+	case ModeShuffleAll:
+		if pb.folder != 0 {
+			pb.shuffledFrom = pb.folder
+		}
+		pb.PlayingOther()
+		return pb.dfp.RandomAll()
+	}
+	return nil
+}
+
+// leaveShuffleAll stops the DFPlayer picking random tracks, it would go on with every next track
+func (pb *Playback) leaveShuffleAll() error {
+	folder := pb.shuffledFrom
+	pb.shuffledFrom = 0
+	if folder == 0 || pb.state != Playing {
+		// the folder resumes on pause
+		pb.folder = folder
+		pb.track = pb.ResumeTrack(folder)
+		return pb.Stop()
+	}
+	if pb.mode == ModeShuffleFolder {
+		return pb.playShuffled(folder)
+	}
+	return pb.play(folder, pb.ResumeTrack(folder))
+}
+
+// NextMode cycles through the folder modes
+func (pb *Playback) NextMode() error {
+	return pb.SetMode(pb.mode.next())
+}
+
+func (pb *Playback) State() PlaybackState {
+	return pb.state
+}
+
+// Folder returns the current folder, 0 if not playing from a folder
+func (pb *Playback) Folder() uint8 {
+	return pb.folder
+}
+
+func (pb *Playback) Track() uint8 {
+	return pb.track
+}
+
+// ResumeTrack returns the track the folder continues at
+func (pb *Playback) ResumeTrack(folder uint8) uint8 {
+	if folder > maxFolders || pb.resume[folder] == 0 {
+		return 1
+	}
+	return pb.resume[folder]
+}
+
+// PressFolder toggles pause of the current folder, any other folder resumes where it was left
+func (pb *Playback) PressFolder(folder uint8) error {
+	if folder == pb.folder && pb.state != Stopped {
+		return pb.TogglePause()
+	}
+	if pb.mode == ModeShuffleFolder {
+		return pb.playShuffled(folder)
+	}
+	return pb.play(folder, pb.ResumeTrack(folder))
+}
+
+// PlayFromStart plays the folder from its first track, shuffled folders get a new order
+func (pb *Playback) PlayFromStart(folder uint8) error {
+	if pb.mode == ModeShuffleFolder {
+		return pb.playShuffled(folder)
+	}
+	return pb.play(folder, 1)
+}
+
+// TogglePause pauses and unpauses, when stopped the last folder resumes
+func (pb *Playback) TogglePause() error {
+	switch pb.state {
+	case Playing:
+		pb.state = Paused
+		pb.commandAt = pb.now()
+		return pb.dfp.Pause()
+	case Paused:
+		pb.state = Playing
+		pb.commandAt = pb.now()
+		return pb.dfp.Unpause()
+	}
+	if pb.folder == 0 {
+		return nil
+	}
+	return pb.play(pb.folder, pb.ResumeTrack(pb.folder))
+}
+
+// Stop stops playback, the folder still resumes at the current track
+func (pb *Playback) Stop() error {
+	pb.state = Stopped
+	pb.commandAt = pb.now()
+	return pb.dfp.Stop()
+}
+
+// Next plays the next track of the folder, the last track stays playing unless the folder repeats
+func (pb *Playback) Next() error {
+	if pb.folder == 0 {
+		pb.commandAt = pb.now()
+		return pb.dfp.PlayNext()
+	}
+	if t, ok := pb.following(); ok {
+		return pb.play(pb.folder, t)
+	}
+	if pb.mode == ModeRepeatFolder {
+		return pb.play(pb.folder, 1)
+	}
+	return nil
+}
+
+// Previous plays the previous track of the folder
+func (pb *Playback) Previous() error {
+	if pb.folder == 0 {
+		pb.commandAt = pb.now()
+		return pb.dfp.PlayPrevious()
+	}
+	if pb.shuffled() {
+		if pb.pos > 0 {
+			pb.pos--
+		}
+		return pb.play(pb.folder, pb.order[pb.pos])
+	}
+	if pb.track <= 1 {
+		return pb.play(pb.folder, 1)
+	}
+	return pb.play(pb.folder, pb.track-1)
+}
+
+// PlayingOther is called when the DFPlayer plays something the playback can't follow, e.g. all tracks shuffled
+func (pb *Playback) PlayingOther() {
+	pb.state = Playing
+	pb.folder = 0
+	pb.track = 0
+	pb.commandAt = pb.now()
+}
+
+// TrackFinished continues with the next track of the folder, after the last track the folder starts over next
+// time. track is the global number the DFPlayer reported.
+func (pb *Playback) TrackFinished(track uint16) error {
+	now := pb.now()
+	if pb.finishedKnown && track == pb.finished && now.Sub(pb.finishedAt) < finishedRepeat {
+		return nil
+	}
+	pb.finished, pb.finishedAt, pb.finishedKnown = track, now, true
+
+	// the DFPlayer repeats one track or shuffles all on its own
+	if pb.state != Playing || pb.folder == 0 || pb.mode == ModeRepeatOne {
+		return nil
+	}
+	return pb.advance()
+}
+
+// advance continues the folder after its track finished
+func (pb *Playback) advance() error {
+	if t, ok := pb.following(); ok {
+		return pb.play(pb.folder, t)
+	}
+	switch pb.mode {
+	case ModeRepeatFolder:
+		return pb.play(pb.folder, 1)
+	case ModeShuffleFolder:
+		// shuffle again for the next round
+		pb.order = nil
+	}
+	debug.Log("folder finished: " + strconv.Itoa(int(pb.folder)))
+	pb.state = Stopped
+	pb.resume[pb.folder] = 1
+	return nil
+}
+
+// following returns the track after the current one, false at the end of the folder
+func (pb *Playback) following() (uint8, bool) {
+	if pb.shuffled() {
+		if pb.pos+1 >= len(pb.order) {
+			return 0, false
+		}
+		pb.pos++
+		return pb.order[pb.pos], true
+	}
+	if pb.track >= pb.fileCount(pb.folder) {
+		return 0, false
+	}
+	return pb.track + 1, true
+}
+
+// shuffled returns whether the current folder plays in a shuffled order
+func (pb *Playback) shuffled() bool {
+	return pb.mode == ModeShuffleFolder && len(pb.order) > 0
+}
+
+// shuffle creates a random order of the tracks of the folder starting with first, 0 picks a random first track
+func (pb *Playback) shuffle(folder, first uint8) {
+	n := pb.fileCount(folder)
+	if n == 0xFF {
+		// unknown size, play in order
+		pb.order = nil
+		return
+	}
+	pb.order = pb.order[:0]
+	for i := 0; i < int(n); i++ {
+		pb.order = append(pb.order, uint8(i+1))
+	}
+	pb.r.Shuffle(len(pb.order), func(i, j int) {
+		pb.order[i], pb.order[j] = pb.order[j], pb.order[i]
+	})
+	pb.pos = 0
+	for i, t := range pb.order {
+		if t == first {
+			pb.order[0], pb.order[i] = pb.order[i], pb.order[0]
+			break
+		}
+	}
+}
+
+func (pb *Playback) playShuffled(folder uint8) error {
+	pb.shuffle(folder, 0)
+	if len(pb.order) == 0 {
+		return pb.play(folder, 1)
+	}
+	return pb.play(folder, pb.order[0])
+}
+
+// Sync corrects the state with the one the DFPlayer reports, e.g. after it stopped on its own. A folder that
+// stopped playing waits for the finished event of its track, if none arrives the folder continues anyway.
+func (pb *Playback) Sync(playing bool) error {
+	now := pb.now()
+	if now.Sub(pb.commandAt) < syncGrace {
+		return nil
+	}
+	if playing || pb.state != Playing {
+		pb.stopSeen = false
+		if playing && pb.state != Playing {
+			pb.state = Playing
+		}
+		return nil
+	}
+	if pb.folder == 0 || pb.mode == ModeRepeatOne {
+		pb.state = Stopped
+		return nil
+	}
+	if !pb.stopSeen {
+		pb.stopSeen, pb.stoppedAt = true, now
+		return nil
+	}
+	if now.Sub(pb.stoppedAt) < finishedWait {
+		return nil
+	}
+	// the event got lost, e.g. it was dropped while sending a command
+	debug.Log("track finished without event")
+	return pb.advance()
+}
+
+func (pb *Playback) play(folder, track uint8) error {
+	debug.Log("playing folder " + strconv.Itoa(int(folder)) + " track " + strconv.Itoa(int(track)))
+	pb.folder = folder
+	pb.track = track
+	if folder <= maxFolders {
+		pb.resume[folder] = track
+	}
+	pb.state = Playing
+	pb.stopSeen = false
+	pb.commandAt = pb.now()
+	err := pb.dfp.PlayFolder(folder, track)
+	if err != nil || pb.mode != ModeRepeatOne {
+		return err
+	}
+	return pb.dfp.SetLoop(true)
+}
+
+// fileCount returns the number of files in the folder, queried once. If the query fails any track is allowed.
+func (pb *Playback) fileCount(folder uint8) uint8 {
+	if folder > maxFolders {
+		return 0xFF
+	}
+	if pb.files[folder] != 0 {
+		return pb.files[folder]
+	}
+	n, err := pb.dfp.QueryFolderFiles(folder)
+	if err != nil || n == 0 {
+		if err != nil {
+			debug.Log("warn: " + errwrap.Wrap("failed to query folder files", err).Error())
+		}
+		return 0xFF
+	}
+	if n > 0xFF {
+		n = 0xFF
+	}
+	pb.files[folder] = uint8(n)
+	return pb.files[folder]
+}
@@ -0,0 +1,192 @@
+package player
+
+import (
+	"strconv"
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+)
+
+type fakeFolderPlayer struct {
+	files uint16
+	sent  []string
+}
+
+func (f *fakeFolderPlayer) PlayFolder(folder uint8, file uint8) error {
+	f.sent = append(f.sent, "play "+strconv.Itoa(int(folder))+"/"+strconv.Itoa(int(file)))
+	return nil
+}
+func (f *fakeFolderPlayer) PlayNext() error     { f.sent = append(f.sent, "next"); return nil }
+func (f *fakeFolderPlayer) PlayPrevious() error { f.sent = append(f.sent, "previous"); return nil }
+func (f *fakeFolderPlayer) Pause() error        { f.sent = append(f.sent, "pause"); return nil }
+func (f *fakeFolderPlayer) Unpause() error      { f.sent = append(f.sent, "unpause"); return nil }
+func (f *fakeFolderPlayer) Stop() error         { f.sent = append(f.sent, "stop"); return nil }
+func (f *fakeFolderPlayer) RandomAll() error    { f.sent = append(f.sent, "random"); return nil }
+func (f *fakeFolderPlayer) SetLoop(enable bool) error {
+	f.sent = append(f.sent, "loop "+strconv.FormatBool(enable))
+	return nil
+}
+func (f *fakeFolderPlayer) QueryFolderFiles(folder uint8) (uint16, error) {
+	return f.files, nil
+}
+
+func (f *fakeFolderPlayer) last() string {
+	return f.sent[len(f.sent)-1]
+}
+
+func TestPlayback_Resume(t *testing.T) {
+	now := time.Unix(0, 0)
+	dfp := &fakeFolderPlayer{files: 3}
+	pb := NewPlayback(dfp, func() time.Time { return now })
+
+	be.NoError(t, pb.PressFolder(1))
+	be.Equal(t, dfp.last(), "play 1/1")
+
+	// the same folder toggles pause
+	be.NoError(t, pb.PressFolder(1))
+	be.Equal(t, dfp.last(), "pause")
+	be.Equal(t, pb.State(), Paused)
+	be.NoError(t, pb.PressFolder(1))
+	be.Equal(t, dfp.last(), "unpause")
+
+	now = now.Add(time.Minute)
+	be.NoError(t, pb.TrackFinished(1))
+	be.Equal(t, dfp.last(), "play 1/2")
+	// the DFPlayer repeats the event
+	be.NoError(t, pb.TrackFinished(1))
+	be.Equal(t, pb.Track(), 2)
+
+	// other folders start at the beginning, coming back resumes
+	be.NoError(t, pb.PressFolder(2))
+	be.Equal(t, dfp.last(), "play 2/1")
+	be.NoError(t, pb.PressFolder(1))
+	be.Equal(t, dfp.last(), "play 1/2")
+
+	be.NoError(t, pb.PlayFromStart(1))
+	be.Equal(t, dfp.last(), "play 1/1")
+}
+
+func TestPlayback_FolderFinished(t *testing.T) {
+	now := time.Unix(0, 0)
+	dfp := &fakeFolderPlayer{files: 2}
+	pb := NewPlayback(dfp, func() time.Time { return now })
+
+	be.NoError(t, pb.PressFolder(4))
+	be.NoError(t, pb.Next())
+	be.Equal(t, dfp.last(), "play 4/2")
+	// no next track after the last one
+	be.NoError(t, pb.Next())
+	be.Equal(t, len(dfp.sent), 2)
+
+	be.NoError(t, pb.TrackFinished(12))
+	be.Equal(t, pb.State(), Stopped)
+	be.Equal(t, pb.ResumeTrack(4), 1)
+
+	be.NoError(t, pb.PressFolder(4))
+	be.Equal(t, dfp.last(), "play 4/1")
+}
+
+func TestPlayback_Sync(t *testing.T) {
+	now := time.Unix(0, 0)
+	dfp := &fakeFolderPlayer{files: 2}
+	pb := NewPlayback(dfp, func() time.Time { return now })
+
+	be.NoError(t, pb.PressFolder(1))
+	// the DFPlayer needs a moment to start
+	be.NoError(t, pb.Sync(false))
+	be.Equal(t, pb.State(), Playing)
+
+	// BUSY goes high before the finished event is handled
+	now = now.Add(time.Minute)
+	be.NoError(t, pb.Sync(false))
+	be.Equal(t, pb.State(), Playing)
+	be.NoError(t, pb.TrackFinished(1))
+	be.Equal(t, dfp.last(), "play 1/2")
+
+	// the event of the last track got lost, the folder finishes anyway
+	now = now.Add(time.Minute)
+	be.NoError(t, pb.Sync(false))
+	be.Equal(t, pb.State(), Playing)
+	now = now.Add(finishedWait)
+	be.NoError(t, pb.Sync(false))
+	be.Equal(t, pb.State(), Stopped)
+
+	// stopped folders resume on pause
+	be.NoError(t, pb.TogglePause())
+	be.Equal(t, dfp.last(), "play 1/1")
+}
+
+func TestPlayback_SyncLostEvent(t *testing.T) {
+	now := time.Unix(0, 0)
+	dfp := &fakeFolderPlayer{files: 3}
+	pb := NewPlayback(dfp, func() time.Time { return now })
+
+	be.NoError(t, pb.PressFolder(1))
+	now = now.Add(time.Minute)
+	be.NoError(t, pb.Sync(false))
+	now = now.Add(finishedWait)
+	be.NoError(t, pb.Sync(false))
+	be.Equal(t, dfp.last(), "play 1/2")
+
+	// the DFPlayer plays the next track
+	be.NoError(t, pb.Sync(true))
+	be.Equal(t, pb.Track(), 2)
+}
+
+func TestPlayback_Modes(t *testing.T) {
+	now := time.Unix(0, 0)
+	dfp := &fakeFolderPlayer{files: 2}
+	pb := NewPlayback(dfp, func() time.Time { return now })
+
+	be.NoError(t, pb.SetMode(ModeRepeatFolder))
+	be.NoError(t, pb.PressFolder(1))
+	be.NoError(t, pb.Next())
+	be.NoError(t, pb.TrackFinished(2))
+	be.Equal(t, dfp.last(), "play 1/1")
+
+	// the DFPlayer loops the track by itself
+	be.NoError(t, pb.NextMode())
+	be.Equal(t, pb.Mode(), ModeShuffleFolder)
+	be.NoError(t, pb.SetMode(ModeRepeatOne))
+	be.Equal(t, dfp.last(), "loop true")
+	now = now.Add(time.Minute)
+	be.NoError(t, pb.TrackFinished(1))
+	be.Equal(t, dfp.last(), "loop true")
+
+	be.NoError(t, pb.SetMode(ModeShuffleAll))
+	be.Equal(t, dfp.sent[len(dfp.sent)-2], "loop false")
+	be.Equal(t, dfp.last(), "random")
+	be.Equal(t, pb.Folder(), 0)
+
+	// leaving it stops the random tracks and resumes the folder
+	be.NoError(t, pb.NextMode())
+	be.Equal(t, pb.Mode(), ModeNormal)
+	be.Equal(t, dfp.last(), "play 1/1")
+	be.NoError(t, pb.Next())
+	be.Equal(t, dfp.last(), "play 1/2")
+
+	// the cycle wraps around without shuffling all tracks
+	be.NoError(t, pb.SetMode(ModeShuffleFolder))
+	be.NoError(t, pb.NextMode())
+	be.Equal(t, pb.Mode(), ModeNormal)
+}
+
+func TestPlayback_ShuffleFolder(t *testing.T) {
+	now := time.Unix(0, 0)
+	dfp := &fakeFolderPlayer{files: 5}
+	pb := NewPlayback(dfp, func() time.Time { return now })
+
+	be.NoError(t, pb.SetMode(ModeShuffleFolder))
+	be.NoError(t, pb.PressFolder(3))
+	played := map[uint8]bool{pb.Track(): true}
+	for i := 0; i < 4; i++ {
+		now = now.Add(time.Minute)
+		be.NoError(t, pb.TrackFinished(uint16(i)))
+		played[pb.Track()] = true
+	}
+	// every track played once, then the folder is over
+	be.Equal(t, len(played), 5)
+	now = now.Add(time.Minute)
+	be.NoError(t, pb.TrackFinished(9))
+	be.Equal(t, pb.State(), Stopped)
+}
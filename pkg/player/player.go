@@ -1,19 +1,84 @@
 package player
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 	"trelligo/pkg/debug"
 	"trelligo/pkg/dfplayer"
+	"trelligo/pkg/draw"
+	"trelligo/pkg/draw/animations"
+	"trelligo/pkg/draw/minigames"
+	"trelligo/pkg/draw/ntdisplay"
 	"trelligo/pkg/errwrap"
 	"trelligo/pkg/neotrellis"
+	"trelligo/pkg/neotrellis/gesture"
 	"trelligo/pkg/seesaw/keypad"
+	"trelligo/pkg/settings"
+	"trelligo/pkg/shims/rand"
 )
 
 const minDelay = time.Millisecond * 100
 
-type keyHandlerFunc func(x, y uint8, e keypad.Edge) error
+// unlockHold how long parents need to hold the unlock chord, unlockDuration how long settings stay unlocked
+const unlockHold = 3 * time.Second
+const unlockDuration = time.Minute
+
+// gameHold how long the game chord needs to be held to start a game
+const gameHold = 2 * time.Second
+
+// idleAfter how long without key presses until the idle screen shows
+const idleAfter = 5 * time.Minute
+
+// night mode dims the keys and lowers the current budget
+const (
+	nightBrightness = 0x40
+	nightBudget     = 100
+)
+
+// colors before gamma correction, the key colors are part of the Layout
+var (
+	colorPlaying = draw.RGB{R: 0x80, G: 0xFF, B: 0xFF}
+	colorVolume  = draw.RGB{R: 0xFF, G: 0xFF, B: 0xFF}
+	colorError   = draw.RGB{R: 0xFF, G: 0, B: 0}
+	colorSleep   = draw.RGB{R: 0x60, G: 0, B: 0xFF}
+)
+
+// errNoCard is shown while the SD card is missing, storageRecheck is how often the card is looked for again
+const errNoCard = "E3"
+const storageRecheck = 5 * time.Second
+
+// layers of the key animation, the keys are the base unless there is a visualizer behind them
+const (
+	layerKeys = iota
+	layerPlaying
+	layerSleep
+	layerText
+)
+
+// keysOverVisualizer lets the visualizer shine through the key colors
+const keysOverVisualizer = 0xC0
+
+// playbackPoll how often the playback state is checked for the visualizer
+const playbackPoll = time.Second
+
+// sleepRingAlpha lets the keys shine through the ring of the sleep timer
+const sleepRingAlpha = 0xA0
+
+// asleepBrightness the keys stay barely visible after the sleep timer expired
+const asleepBrightness = 0x10
+
+// outputSD wakes the DFPlayer up from sleep
+const outputSD = 2
+
+// playingPulse is the period of the pulse on the key of the playing folder
+const playingPulse = 2 * time.Second
+
+// ErrLocked is returned when changing parental settings without unlocking them first
+var ErrLocked = errors.New("settings are locked")
+
+type keyHandlerFunc func(e gesture.Event) error
 
 type xy = uint8
 
@@ -26,95 +91,746 @@ type VolumeGetter interface {
 }
 
 type Player struct {
-	nt  *neotrellis.Device
-	dfp *dfplayer.Player
+	nt      *neotrellis.Device
+	dfp     *dfplayer.Player
+	display *ntdisplay.Display
 
 	handlers    []keyHandlerFunc
+	gestures    *gesture.Recognizer
 	needRefresh bool
 
-	vol VolumeGetter
+	unlockChord   int
+	unlockedUntil time.Time
+	now           func() time.Time
+
+	gameChord int
+	newGame   func() minigames.Game
+	game      *minigames.Runner
+
+	idleScreen *animations.Life
+	idle       bool
+	lastKeyAt  time.Time
+
+	layout     Layout
+	playback   *Playback
+	playingKey int
+
+	visualizer      *animations.Visualizer
+	busy            *dfplayer.Busy
+	keyMask         draw.Mask
+	playbackCheckAt time.Time
+	trackStale      bool
+
+	vol       VolumeGetter
+	policy    *VolumePolicy
+	adjusting bool
+	store     *settings.Store
+
+	nightMode bool
+	day       draw.Limiter
+
+	text           *animations.Marquee
+	noCard         bool
+	storageCheckAt time.Time
+	lastVolume     int
+	sentVolume     int
+
+	sleep       *SleepTimer
+	sleepBuf    draw.Buffer4x4
+	asleep      bool
+	beforeSleep draw.Limiter
 
 	lastUpdate time.Time
-	buf        neotrellis.PixelBuffer
+	buf        draw.Buffer4x4
+	anim       *animations.Layers
 }
 
-// [  0  1  2  3 ]
-// [  4  5  6  7 ]
-// [  8  9 10 11 ]
-// [  <  D  >  x ]
-
+// New creates a player with the DefaultLayout
 func New(nt *neotrellis.Device, dfp *dfplayer.Player, getter VolumeGetter) (*Player, error) {
+	return NewWithLayout(nt, dfp, getter, DefaultLayout())
+}
+
+// NewWithLayout creates a player with the keys of the layout, see DefaultLayout.
+//
+// The chords don't depend on the layout, they use the keys of the bottom row:
+// Holding the 2nd and 3rd key together for 3 seconds unlocks the settings, see SetVolumePolicy.
+// Holding the 1st and 4th key together for 2 seconds starts a game, see SetGame.
+// After 5 minutes without key presses or music playing the idle screen shows, see SetIdleScreen. Pressing keys
+// seeds cells, long-pressing any key returns to the player.
+func NewWithLayout(nt *neotrellis.Device, dfp *dfplayer.Player, getter VolumeGetter, layout Layout) (*Player, error) {
 
 	p := &Player{
 		nt:          nt,
 		dfp:         dfp,
+		display:     ntdisplay.NewDisplay(nt),
 		handlers:    make([]keyHandlerFunc, 16),
 		needRefresh: true,
 		vol:         getter,
-		buf:         neotrellis.NewPixelBuffer(),
+		gestures:    gesture.New(gesture.DefaultConfig()),
+		now:         time.Now,
+		lastVolume:  -1,
+		sentVolume:  -1,
+		layout:      layout,
+		playingKey:  -1,
 	}
+	p.playback = NewPlayback(dfp, func() time.Time { return p.now() })
+	p.sleep = NewSleepTimer(func() time.Time { return p.now() })
 
+	// gestures need both edges
 	for i := uint8(0); i < 16; i++ {
-		err := nt.ConfigureKeypad(i/4, i%4, keypad.EdgeRising, true)
-		if err != nil {
-			return nil, fmt.Errorf("failed to enable keys: %w", err)
+		for _, edge := range []keypad.Edge{keypad.EdgeRising, keypad.EdgeFalling} {
+			err := nt.ConfigureKeypad(i/4, i%4, edge, true)
+			if err != nil {
+				return nil, fmt.Errorf("failed to enable keys: %w", err)
+			}
 		}
 	}
 
 	nt.SetKeyHandleFunc(func(x, y uint8, e keypad.Edge) error {
-		f := p.handlers[newXy(x, y)]
-		if f == nil {
-			return nil
+		p.lastKeyAt = p.now()
+		if p.idle && e == keypad.EdgeRising {
+			p.idleScreen.Seed(x, y, p.lastKeyAt)
 		}
-		return f(x, y, e)
+		if p.game != nil {
+			err := p.game.HandleKey(x, y, e)
+			if err != nil {
+				return err
+			}
+		}
+		// gestures keep track of held keys even during a game
+		return p.gestures.HandleKey(x, y, e, p.now())
 	})
+	p.gestures.SetHandleFunc(p.handleGesture)
+
+	for i, k := range layout {
+		x, y := uint8(i)&0x3, uint8(i)>>2
+		p.buf.Set(x, y, k.Color.RGB())
+		h := p.keyHandler(k, x, y)
+		if h != nil {
+			p.addHandler(newXy(x, y), h)
+		}
+	}
+
+	p.unlockChord = p.gestures.AddChord(unlockHold, neotrellis.PositionFromXY(1, 0), neotrellis.PositionFromXY(2, 0))
+	p.gameChord = p.gestures.AddChord(gameHold, neotrellis.PositionFromXY(0, 0), neotrellis.PositionFromXY(3, 0))
+
+	// the pulse on the playing folder, the sleep timer and text are hidden until needed
+	p.anim = animations.NewLayers(animations.NewStatic(&p.buf), animations.Layer{}, animations.Layer{}, animations.Layer{},
+		animations.Layer{})
+	p.checkStorage()
+
+	err := p.display.WriteBuffer(&p.buf)
+	if err != nil {
+		return nil, err
+	}
 
-	nFolders := 9
+	p.lastUpdate = time.Now()
+	p.lastKeyAt = p.now()
+	return p, nil
+}
 
-	for i := 0; i < nFolders; i++ {
-		folder := uint8(i + 1)
-		y := uint8(3 - (i / 4))
-		x := uint8(i % 4)
-		p.buf.SetPixel(x, y, neotrellis.RGB{0, 100, 150})
-		p.addHandler(newXy(x, y), func(x, y uint8, e keypad.Edge) error {
-			debug.Log("playing folder: " + strconv.Itoa(int(folder)))
-			return dfp.PlayFolder(folder, 1)
+// keyHandler creates the handler of a key of the layout, nil if the key does nothing
+func (p *Player) keyHandler(k Key, x, y uint8) keyHandlerFunc {
+	switch k.Action {
+	case ActionPlayFolder:
+		folder := k.Folder
+		return func(e gesture.Event) error {
+			switch e.Type {
+			case gesture.Tap:
+				p.showFolder(folder)
+				return p.playback.PressFolder(folder)
+			case gesture.LongPress:
+				debug.Log("playing folder from the start: " + strconv.Itoa(int(folder)))
+				p.showFolder(folder)
+				return p.playback.PlayFromStart(folder)
+			}
+			return nil
+		}
+	case ActionNext:
+		return onTap(func() error {
+			p.trackStale = true
+			return p.playback.Next()
+		})
+	case ActionPrevious:
+		return onTap(func() error {
+			p.trackStale = true
+			return p.playback.Previous()
+		})
+	case ActionStop:
+		return onTap(p.playback.Stop)
+	case ActionPause:
+		return onTap(p.playback.TogglePause)
+	case ActionVolumeUp:
+		return onTap(func() error {
+			return p.nudgeVolume(1)
+		})
+	case ActionVolumeDown:
+		return onTap(func() error {
+			return p.nudgeVolume(-1)
+		})
+	case ActionShuffle:
+		return onTap(func() error {
+			if p.playback.Mode() == ModeShuffleAll {
+				return p.setMode(ModeNormal)
+			}
+			return p.setMode(ModeShuffleAll)
+		})
+	case ActionLoop:
+		return onTap(func() error {
+			if p.playback.Mode() == ModeRepeatOne {
+				return p.setMode(ModeNormal)
+			}
+			return p.setMode(ModeRepeatOne)
+		})
+	case ActionMode:
+		return onTap(func() error {
+			return p.setMode(p.playback.Mode().next())
+		})
+	case ActionSleepTimer:
+		return onTap(func() error {
+			if p.sleep.Running() {
+				p.CancelSleepTimer()
+			} else {
+				p.StartSleepTimer(DefaultSleepAfter)
+			}
+			return nil
 		})
 	}
+	return nil
+}
 
-	// play previous
-	p.buf.SetPixel(0, 0, neotrellis.RGB{0, 100, 150})
-	p.addHandler(newXy(0, 0), func(x, y uint8, e keypad.Edge) error {
-		return dfp.PlayPrevious()
-	})
+func (p *Player) addHandler(o xy, h keyHandlerFunc) {
+	p.handlers[o] = h
+	p.keyMask |= draw.MaskOf(o&0x3, o>>2)
+}
+
+// onTap creates a handler that only reacts to taps
+func onTap(f func() error) keyHandlerFunc {
+	return func(e gesture.Event) error {
+		if e.Type != gesture.Tap {
+			return nil
+		}
+		return f()
+	}
+}
 
-	// play next
-	p.buf.SetPixel(1, 0, neotrellis.RGB{0, 150, 100})
-	p.addHandler(newXy(1, 0), func(x, y uint8, e keypad.Edge) error {
-		return dfp.PlayNext()
+func (p *Player) handleGesture(e gesture.Event) error {
+	if p.game != nil {
+		return nil
+	}
+	if p.asleep {
+		if e.Type == gesture.Tap || e.Type == gesture.LongPress {
+			return p.wake()
+		}
+		return nil
+	}
+	if p.idle {
+		if e.Type == gesture.LongPress {
+			p.leaveIdle()
+		}
+		return nil
+	}
+	if e.Type == gesture.Chord {
+		switch e.Chord {
+		case p.unlockChord:
+			debug.Log("settings unlocked")
+			p.unlockedUntil = p.now().Add(unlockDuration)
+			if p.policy != nil {
+				// the knob picks the maximum volume until the settings lock again
+				p.policy.StartAdjust()
+				p.adjusting = true
+			}
+		case p.gameChord:
+			p.startGame()
+		}
+		return nil
+	}
+
+	f := p.handlers[newXy(e.X, e.Y)]
+	if f == nil {
+		return nil
+	}
+	return f(e)
+}
+
+// SetVisualizer shows the visualizer behind the key colors. busy is optional, without it the playback state is
+// queried via UART.
+func (p *Player) SetVisualizer(v *animations.Visualizer, busy *dfplayer.Busy) {
+	p.visualizer = v
+	p.busy = busy
+	p.anim.SetBase(v)
+	p.anim.SetLayer(layerKeys, animations.Layer{
+		Animation: animations.NewStatic(&p.buf),
+		Alpha:     keysOverVisualizer,
+		Mask:      p.keyMask,
 	})
+}
+
+// processPlayback keeps the playback state and the visualizer in sync with the DFPlayer
+func (p *Player) processPlayback() {
+	now := p.now()
+	if p.visualizer == nil && p.playback.State() == Stopped || now.Before(p.playbackCheckAt) {
+		return
+	}
+	p.playbackCheckAt = now.Add(playbackPoll)
+
+	playing, err := p.playing()
+	if err != nil {
+		debug.Log("warn: " + errwrap.Wrap("failed to query playback state", err).Error())
+		return
+	}
+	err = p.playback.Sync(playing)
+	if err != nil {
+		debug.Log("warn: " + errwrap.Wrap("failed to continue folder", err).Error())
+	}
+	if p.visualizer == nil {
+		return
+	}
+	if playing && !p.visualizer.Playing() {
+		p.trackStale = true
+	}
+	p.visualizer.SetPlaying(playing)
+
+	if !playing || !p.trackStale {
+		return
+	}
+	track, err := p.dfp.QueryCurrentTrack()
+	if err != nil {
+		debug.Log("warn: " + errwrap.Wrap("failed to query track", err).Error())
+		return
+	}
+	p.trackStale = false
+	p.visualizer.SetTrack(track)
+}
+
+func (p *Player) playing() (bool, error) {
+	if p.busy != nil {
+		return p.busy.Playing(), nil
+	}
+	s, err := p.dfp.QueryState()
+	return s == dfplayer.StatePlaying, err
+}
+
+// SetIdleScreen enables the idle screen, a Game of Life that keys can seed cells of
+func (p *Player) SetIdleScreen(l *animations.Life) {
+	p.idleScreen = l
+}
+
+// processIdle shows the idle screen once no key was pressed for a while, returns whether it is showing
+func (p *Player) processIdle() (bool, error) {
+	if p.idleScreen == nil {
+		return false, nil
+	}
+	now := p.now()
+	if p.playback.State() == Playing {
+		// the keys stay usable while music plays, idle counts from when it stopped
+		p.lastKeyAt = now
+		if p.idle {
+			p.leaveIdle()
+		}
+		return false, nil
+	}
+	if !p.idle && now.Sub(p.lastKeyAt) >= idleAfter {
+		debug.Log("showing idle screen")
+		p.idle = true
+		p.idleScreen.Reseed()
+	}
+	if !p.idle {
+		return false, nil
+	}
+
+	if p.idleScreen.Update(now) {
+		err := p.idleScreen.Draw(p.display)
+		if err != nil {
+			return true, errwrap.Wrap("player failed to draw idle screen", err)
+		}
+	}
+	return true, nil
+}
+
+func (p *Player) leaveIdle() {
+	debug.Log("leaving idle screen")
+	p.idle = false
+	p.needRefresh = true
+}
+
+// SetGame enables the game mode, newGame creates the game to play each time
+func (p *Player) SetGame(newGame func() minigames.Game) {
+	p.newGame = newGame
+}
+
+func (p *Player) startGame() {
+	if p.newGame == nil {
+		return
+	}
+	debug.Log("starting game")
+	p.game = minigames.NewRunner(p.newGame(), p.display)
+}
+
+// processGame steps the game, once the score was shown the keys are back to the player
+func (p *Player) processGame() error {
+	done, err := p.game.Step(p.now())
+	if err != nil {
+		return errwrap.Wrap("player failed to step game", err)
+	}
+	if done {
+		debug.Log("game over, score: " + strconv.Itoa(p.game.Game().Score()))
+		p.game = nil
+		p.needRefresh = true
+	}
+	return nil
+}
+
+// Unlocked returns whether the parental settings are currently unlocked
+func (p *Player) Unlocked() bool {
+	return p.now().Before(p.unlockedUntil)
+}
+
+// Limiter returns the limiter of the keys, e.g. to set the global brightness
+func (p *Player) Limiter() *draw.Limiter {
+	return p.display.Limiter()
+}
+
+// SetNightMode dims the keys and lowers the current budget, disabling it restores the previous limits
+func (p *Player) SetNightMode(enable bool) {
+	if enable == p.nightMode {
+		return
+	}
+	p.nightMode = enable
+	if p.policy != nil {
+		p.policy.SetNight(enable)
+	}
+	p.needRefresh = true
+
+	l := p.display.Limiter()
+	if !enable {
+		*l = p.day
+		return
+	}
+	p.day = *l
+	if l.Brightness() > nightBrightness {
+		l.SetBrightness(nightBrightness)
+	}
+	if l.Budget() == 0 || l.Budget() > nightBudget {
+		l.SetBudget(nightBudget)
+	}
+}
+
+// Layout returns the layout of the keys, e.g. to persist it
+func (p *Player) Layout() Layout {
+	return p.layout
+}
+
+// SetVolumePolicy limits the volume of the knob, see VolumePolicy. While the settings are unlocked the knob
+// sets the maximum volume.
+func (p *Player) SetVolumePolicy(s VolumeSettings) {
+	p.policy = NewVolumePolicy(p.vol, s, func() time.Time { return p.now() })
+	p.policy.SetNight(p.nightMode)
+	p.vol = p.policy
+}
+
+// VolumePolicy returns the volume policy, nil if there is none
+func (p *Player) VolumePolicy() *VolumePolicy {
+	return p.policy
+}
+
+// SetVolumeSettings changes the volume limits, the settings need to be unlocked
+func (p *Player) SetVolumeSettings(s VolumeSettings) error {
+	if p.policy == nil {
+		return errors.New("no volume policy")
+	}
+	if !p.Unlocked() {
+		return ErrLocked
+	}
+	err := s.Validate()
+	if err != nil {
+		return err
+	}
+	p.policy.SetSettings(s)
+	return p.saveSettings()
+}
+
+// SetSettingsStore persists changes of the settings, e.g. the volume limits
+func (p *Player) SetSettingsStore(st *settings.Store) {
+	p.store = st
+}
+
+func (p *Player) saveSettings() error {
+	if p.store == nil {
+		return nil
+	}
+	s := p.layout.Settings()
+	if p.policy != nil {
+		s.Volume = p.policy.Settings().Settings()
+	}
+	err := p.store.Save(s)
+	if err != nil {
+		return errwrap.Wrap("player failed to save settings", err)
+	}
+	return nil
+}
+
+// processLock takes the knob position as the maximum volume once the settings locked again
+func (p *Player) processLock() {
+	if !p.adjusting || p.Unlocked() {
+		return
+	}
+	p.adjusting = false
+	if !p.policy.FinishAdjust() {
+		return
+	}
+	debug.Log(fmt.Sprintf("maximum volume set to %d", p.policy.Limits().Max))
+	err := p.saveSettings()
+	if err != nil {
+		debug.Log("warn: " + err.Error())
+	}
+}
 
-	//stop
-	p.buf.SetPixel(2, 0, neotrellis.RGB{0xFF, 0, 0})
-	p.addHandler(newXy(2, 0), func(x, y uint8, e keypad.Edge) error {
-		return dfp.Stop()
+// NightMode returns whether night mode is enabled
+func (p *Player) NightMode() bool {
+	return p.nightMode
+}
+
+// PlayFolder plays the folder as if its key was tapped, e.g. for a card placed on a reader
+func (p *Player) PlayFolder(folder uint8) error {
+	p.lastKeyAt = p.now()
+	if p.idle {
+		p.leaveIdle()
+	}
+	p.showFolder(folder)
+	return p.playback.PressFolder(folder)
+}
+
+// setMode changes the playback mode and shows it on the mode keys
+func (p *Player) setMode(m PlaybackMode) error {
+	p.trackStale = true
+	err := p.playback.SetMode(m)
+	m = p.playback.Mode()
+	for i, k := range p.layout {
+		if k.Action == ActionMode {
+			p.buf.Set(uint8(i)&0x3, uint8(i)>>2, k.color(m).RGB())
+		}
+	}
+	return err
+}
+
+// SetClock replaces the clock the player goes by, e.g. to simulate it. It restarts the idle timeout, so it should
+// be set before the first Process.
+func (p *Player) SetClock(now func() time.Time) {
+	p.now = now
+	p.lastKeyAt = now()
+	p.storageCheckAt = time.Time{}
+}
+
+// SetRand sets the source of the folder shuffle
+func (p *Player) SetRand(r *rand.Rand) {
+	p.playback.SetRand(r)
+}
+
+// Playback returns the playback state and resume positions
+func (p *Player) Playback() *Playback {
+	return p.playback
+}
+
+// syncPlaying pulses the key of the playing folder
+func (p *Player) syncPlaying() {
+	key := -1
+	if p.playback.State() == Playing {
+		key = p.layout.keyOf(p.playback.Folder())
+	}
+	if key == p.playingKey {
+		return
+	}
+	p.playingKey = key
+	if key < 0 {
+		p.hidePlaying()
+		return
+	}
+	p.showPlaying(uint8(key)&0x3, uint8(key)>>2)
+}
+
+// processEvents follows the tracks the DFPlayer finished
+func (p *Player) processEvents() {
+	for {
+		e, ok, err := p.dfp.PollEvent()
+		if err != nil {
+			debug.Log("warn: " + errwrap.Wrap("failed to poll player events", err).Error())
+			return
+		}
+		if !ok {
+			return
+		}
+		switch {
+		case e.TrackFinished():
+			p.trackStale = true
+			err = p.playback.TrackFinished(e.Argument)
+			if err != nil {
+				debug.Log("warn: " + errwrap.Wrap("failed to continue folder", err).Error())
+			}
+		case e.StorageChanged():
+			p.checkStorage()
+		}
+	}
+}
+
+// StartSleepTimer stops playback after d, the volume fades down over the last minute
+func (p *Player) StartSleepTimer(d time.Duration) {
+	debug.Log("sleep timer started: " + d.String())
+	p.sleep.Start(d)
+	p.sleep.drawRing(&p.sleepBuf, colorSleep)
+	p.anim.SetLayer(layerSleep, animations.Layer{
+		Animation: animations.NewStatic(&p.sleepBuf),
+		Alpha:     sleepRingAlpha,
+		Mask:      ringMask(),
 	})
+}
+
+// CancelSleepTimer cancels the sleep timer, a faded volume is restored
+func (p *Player) CancelSleepTimer() {
+	debug.Log("sleep timer cancelled")
+	p.sleep.Cancel()
+	p.anim.SetLayer(layerSleep, animations.Layer{})
+}
+
+// SleepTimer returns the sleep timer, e.g. to show the remaining time
+func (p *Player) SleepTimer() *SleepTimer {
+	return p.sleep
+}
 
-	err := p.nt.WriteColors(p.buf)
+// Asleep returns whether the sleep timer expired and the player sleeps until a key is pressed
+func (p *Player) Asleep() bool {
+	return p.asleep
+}
+
+// processSleep shrinks the ring of the sleep timer, once it expired playback stops and the DFPlayer goes to sleep
+func (p *Player) processSleep() error {
+	if !p.sleep.Running() {
+		return nil
+	}
+	if !p.sleep.Expired() {
+		p.sleep.drawRing(&p.sleepBuf, colorSleep)
+		return nil
+	}
+
+	debug.Log("sleep timer expired")
+	p.sleep.Cancel()
+	p.anim.SetLayer(layerSleep, animations.Layer{})
+	err := p.playback.Stop()
 	if err != nil {
-		return nil, err
+		return errwrap.Wrap("player failed to stop for sleep", err)
+	}
+
+	p.asleep = true
+	p.needRefresh = true
+	l := p.display.Limiter()
+	p.beforeSleep = *l
+	if l.Brightness() > asleepBrightness {
+		l.SetBrightness(asleepBrightness)
 	}
-	err = p.nt.ShowPixels()
+	err = p.dfp.Sleep()
 	if err != nil {
-		return nil, err
+		return errwrap.Wrap("player failed to send the DFPlayer to sleep", err)
 	}
+	return nil
+}
 
-	p.lastUpdate = time.Now()
-	return p, nil
+// wake restores the keys and the volume after sleeping
+func (p *Player) wake() error {
+	debug.Log("waking up")
+	p.asleep = false
+	p.needRefresh = true
+	*p.display.Limiter() = p.beforeSleep
+	// the volume was faded out
+	p.sentVolume = -1
+	return p.dfp.SetOutputDevice(outputSD)
 }
 
-func (p *Player) addHandler(o xy, h keyHandlerFunc) {
-	p.handlers[o] = h
+// nudgeVolume moves the volume a step from the knob position, the volume policy keeps it within its limits and
+// the next Process sends it
+func (p *Player) nudgeVolume(delta int) error {
+	if p.policy != nil {
+		p.policy.Nudge(delta)
+		return nil
+	}
+	// nothing to limit, the DFPlayer steps on its own
+	if delta > 0 {
+		return p.dfp.VolumeUp()
+	}
+	return p.dfp.VolumeDown()
+}
+
+// applyVolume sends the knob volume, faded by the sleep timer, if it changed
+func (p *Player) applyVolume() error {
+	if p.lastVolume < 0 || p.asleep {
+		return nil
+	}
+	v := p.sleep.Volume(p.lastVolume)
+	if v == p.sentVolume {
+		return nil
+	}
+	debug.Log(fmt.Sprintf("updating volume: %2d", v))
+	err := p.dfp.SetVolume(uint8(v))
+	if err != nil {
+		return fmt.Errorf("failed to update volume to %d: %w", v, err)
+	}
+	p.sentVolume = v
+	return nil
+}
+
+// showPlaying pulses the key at x/y
+func (p *Player) showPlaying(x, y uint8) {
+	p.anim.SetLayer(layerPlaying, animations.Layer{
+		Animation: animations.NewPulse(colorPlaying, draw.MaskOf(x, y), playingPulse),
+		Alpha:     0xFF,
+		Mask:      draw.MaskOf(x, y),
+	})
+}
+
+func (p *Player) hidePlaying() {
+	p.anim.SetLayer(layerPlaying, animations.Layer{})
+}
+
+// showText scrolls the text over the keys, looping until hidden
+func (p *Player) showText(text string, c draw.RGB, loop bool) {
+	p.text = animations.NewMarquee(text, c)
+	p.text.SetLoop(loop)
+	p.anim.SetLayer(layerText, animations.Layer{Animation: p.text, Alpha: 0xFF})
+}
+
+func (p *Player) hideText() {
+	p.text = nil
+	p.anim.SetLayer(layerText, animations.Layer{})
+}
+
+// checkStorage shows an error while the SD card is missing
+func (p *Player) checkStorage() {
+	p.storageCheckAt = p.now().Add(storageRecheck)
+
+	s, err := p.dfp.QueryStorage()
+	if err != nil {
+		debug.Log("warn: " + errwrap.Wrap("failed to query storage", err).Error())
+		return
+	}
+
+	noCard := !s.Has(dfplayer.StorageSD)
+	if noCard == p.noCard {
+		return
+	}
+	p.noCard = noCard
+	if noCard {
+		debug.Log("SD card missing")
+		p.showText(errNoCard, colorError, true)
+	} else {
+		p.hideText()
+	}
+}
+
+// showFolder updates the visualizer to the folder about to play
+func (p *Player) showFolder(folder uint8) {
+	p.trackStale = true
+	if p.visualizer != nil {
+		p.visualizer.SetFolder(folder)
+	}
 }
 
 func (p *Player) Process() error {
@@ -124,31 +840,74 @@ func (p *Player) Process() error {
 		time.Sleep(minDelay - diff)
 	}
 
+	// events first, sending commands drops pending ones
+	p.processEvents()
+	p.processLock()
+
 	v, updated := p.vol.Get()
 	if updated {
-		debug.Log(fmt.Sprintf("updating volume: %2d", v))
-		err := p.dfp.SetVolume(uint8(v))
-		if err != nil {
-			return fmt.Errorf("failed to update volume to %d: %w", v, err)
+		// the initial volume is no news, only show when the knob moved
+		if p.lastVolume >= 0 && !p.noCard && !p.asleep {
+			p.showText(strconv.Itoa(v), colorVolume, false)
 		}
+		p.lastVolume = v
+	}
+	err := p.applyVolume()
+	if err != nil {
+		return err
 	}
 
-	err := p.nt.ProcessKeyEvents()
+	if p.noCard && !p.now().Before(p.storageCheckAt) {
+		p.checkStorage()
+	}
+
+	err = p.nt.ProcessKeyEvents()
 	if err != nil {
 		err = errwrap.Wrap("player failed to process key events", err)
 		debug.Log("warn: " + err.Error())
 	}
 
-	err = p.nt.WriteColors(p.buf)
+	err = p.gestures.Update(p.now())
 	if err != nil {
-		err = errwrap.Wrap("player failed to update pixel values", err)
+		err = errwrap.Wrap("player failed to process gestures", err)
 		debug.Log("warn: " + err.Error())
 	}
 
-	err = p.nt.ShowPixels()
+	if p.game != nil {
+		return p.processGame()
+	}
+
+	err = p.processSleep()
 	if err != nil {
-		return errwrap.Wrap("player failed to process pixel refresh", err)
+		return err
+	}
+	if p.asleep {
+		return p.refresh()
+	}
+
+	p.processPlayback()
+	p.syncPlaying()
+
+	idle, err := p.processIdle()
+	if idle || err != nil {
+		return err
 	}
 
+	if p.text != nil && p.text.Done() {
+		p.hideText()
+	}
+
+	return p.refresh()
+}
+
+// refresh draws the keys if anything changed
+func (p *Player) refresh() error {
+	if p.anim.Update(p.now()) || p.needRefresh {
+		p.needRefresh = false
+		err := p.anim.Draw(p.display)
+		if err != nil {
+			return errwrap.Wrap("player failed to process pixel refresh", err)
+		}
+	}
 	return nil
 }
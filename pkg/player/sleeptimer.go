@@ -0,0 +1,103 @@
+package player
+
+import (
+	"time"
+	"trelligo/pkg/draw"
+)
+
+// DefaultSleepAfter is how long the sleep timer runs when started by its key
+const DefaultSleepAfter = 30 * time.Minute
+
+// sleepFade the volume fades down over the last minute
+const sleepFade = time.Minute
+
+// sleepRing are the outer keys clockwise from the top left, the ring shrinks as the timer runs down
+var sleepRing = [12]xy{
+	newXy(0, 3), newXy(1, 3), newXy(2, 3), newXy(3, 3),
+	newXy(3, 2), newXy(3, 1), newXy(3, 0), newXy(2, 0),
+	newXy(1, 0), newXy(0, 0), newXy(0, 1), newXy(0, 2),
+}
+
+// SleepTimer stops playback after a while. It only depends on the clock, so it doesn't matter how often it is
+// looked at.
+type SleepTimer struct {
+	now      func() time.Time
+	duration time.Duration
+	until    time.Time
+	running  bool
+}
+
+func NewSleepTimer(now func() time.Time) *SleepTimer {
+	return &SleepTimer{now: now}
+}
+
+// Start starts the timer, restarting it if it is running
+func (s *SleepTimer) Start(d time.Duration) {
+	s.duration = d
+	s.until = s.now().Add(d)
+	s.running = true
+}
+
+func (s *SleepTimer) Cancel() {
+	s.running = false
+}
+
+func (s *SleepTimer) Running() bool {
+	return s.running
+}
+
+// Remaining returns the time until the timer expires, 0 if it isn't running
+func (s *SleepTimer) Remaining() time.Duration {
+	if !s.running {
+		return 0
+	}
+	r := s.until.Sub(s.now())
+	if r < 0 {
+		return 0
+	}
+	return r
+}
+
+// Expired returns whether the timer is running and the time is up
+func (s *SleepTimer) Expired() bool {
+	return s.running && s.Remaining() == 0
+}
+
+// Volume fades v down to 0 over the last minute, it is returned unchanged otherwise
+func (s *SleepTimer) Volume(v int) int {
+	r := s.Remaining()
+	if !s.running || r >= sleepFade {
+		return v
+	}
+	// round up, the volume only reaches 0 when the time is up
+	return int((int64(v)*int64(r) + int64(sleepFade) - 1) / int64(sleepFade))
+}
+
+// Ring returns how many of n keys are lit, rounded up so the last key stays lit until the timer expires
+func (s *SleepTimer) Ring(n int) int {
+	if !s.running || s.duration <= 0 {
+		return 0
+	}
+	r := int64(s.Remaining())
+	return int((int64(n)*r + int64(s.duration) - 1) / int64(s.duration))
+}
+
+// drawRing lights the remaining part of the ring, the spent part is dark
+func (s *SleepTimer) drawRing(buf *draw.Buffer4x4, c draw.RGB) {
+	lit := s.Ring(len(sleepRing))
+	for i, o := range sleepRing {
+		if i < lit {
+			buf.Set(o&0x3, o>>2, c)
+		} else {
+			buf.Set(o&0x3, o>>2, draw.RGB{})
+		}
+	}
+}
+
+func ringMask() draw.Mask {
+	var m draw.Mask
+	for _, o := range sleepRing {
+		m |= draw.MaskOf(o&0x3, o>>2)
+	}
+	return m
+}
@@ -0,0 +1,55 @@
+package player
+
+import (
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/draw"
+)
+
+func TestSleepTimer(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := NewSleepTimer(func() time.Time { return now })
+	be.Equal(t, s.Volume(20), 20)
+	be.Equal(t, s.Ring(12), 0)
+
+	s.Start(12 * time.Minute)
+	be.Equal(t, s.Ring(12), 12)
+
+	now = now.Add(30 * time.Second)
+	be.Equal(t, s.Ring(12), 12)
+	now = now.Add(30 * time.Second)
+	be.Equal(t, s.Ring(12), 11)
+	be.Equal(t, s.Volume(20), 20)
+
+	// the volume fades over the last minute
+	now = now.Add(10*time.Minute + 30*time.Second)
+	be.Equal(t, s.Ring(12), 1)
+	be.Equal(t, s.Volume(20), 10)
+	be.Equal(t, s.Expired(), false)
+
+	// the timer doesn't care how late it is looked at
+	now = now.Add(time.Hour)
+	be.Equal(t, s.Expired(), true)
+	be.Equal(t, s.Volume(20), 0)
+
+	s.Cancel()
+	be.Equal(t, s.Expired(), false)
+	be.Equal(t, s.Volume(20), 20)
+}
+
+func TestSleepTimer_drawRing(t *testing.T) {
+	now := time.Unix(0, 0)
+	s := NewSleepTimer(func() time.Time { return now })
+	s.Start(12 * time.Minute)
+	now = now.Add(6 * time.Minute)
+
+	var buf draw.Buffer4x4
+	c := draw.RGB{R: 1}
+	s.drawRing(&buf, c)
+	be.Equal(t, buf[0*4+3], c)
+	be.Equal(t, buf[3*4+2], c)
+	// the spent half from the bottom right on is dark
+	be.Equal(t, buf[3*4+0], draw.RGB{})
+	be.Equal(t, buf[0*4+2], draw.RGB{})
+}
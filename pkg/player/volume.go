@@ -0,0 +1,236 @@
+package player
+
+import (
+	"fmt"
+	"time"
+	"trelligo/pkg/settings"
+)
+
+// maxVolume of the DFPlayer
+const maxVolume = 30
+
+// Curve maps the knob onto the volume range
+type Curve uint8
+
+const (
+	CurveLinear Curve = iota
+	// CurveQuadratic gives the knob finer control at low volumes
+	CurveQuadratic
+	curveCount
+)
+
+// VolumeLimits map the knob onto [Min, Max], the knob turned all the way down is always silent
+type VolumeLimits struct {
+	Min   uint8
+	Max   uint8
+	Curve Curve
+}
+
+// Map returns the volume of the knob position in the range [0,30]
+func (l VolumeLimits) Map(knob int) int {
+	if knob <= 0 {
+		return 0
+	}
+	if knob > maxVolume {
+		knob = maxVolume
+	}
+	t := knob
+	if l.Curve == CurveQuadratic {
+		t = (knob*knob + maxVolume - 1) / maxVolume
+	}
+	span := int(l.Max) - int(l.Min)
+	return int(l.Min) + (span*t+maxVolume/2)/maxVolume
+}
+
+// VolumeSettings are the limits by day and by night. Night is from NightFrom until NightUntil, hours of the day,
+// equal hours disable the clock.
+type VolumeSettings struct {
+	Day        VolumeLimits
+	Night      VolumeLimits
+	NightFrom  uint8
+	NightUntil uint8
+}
+
+// DefaultVolumeSettings keep the volume reasonable for kids, the board has no real-time clock so night is
+// only enabled along with the night mode of the player.
+func DefaultVolumeSettings() VolumeSettings {
+	return VolumeSettings{
+		Day:   VolumeLimits{Min: 3, Max: 24, Curve: CurveQuadratic},
+		Night: VolumeLimits{Min: 2, Max: 12, Curve: CurveQuadratic},
+	}
+}
+
+// Validate checks the limits are in range
+func (s VolumeSettings) Validate() error {
+	for _, l := range []VolumeLimits{s.Day, s.Night} {
+		// a maximum of 0 would silence the box
+		if l.Min > l.Max || l.Max == 0 || l.Max > maxVolume {
+			return fmt.Errorf("invalid volume range [%d,%d]", l.Min, l.Max)
+		}
+		if l.Curve >= curveCount {
+			return fmt.Errorf("unknown volume curve %d", l.Curve)
+		}
+	}
+	if s.NightFrom > 23 || s.NightUntil > 23 {
+		return fmt.Errorf("invalid night hours %d-%d", s.NightFrom, s.NightUntil)
+	}
+	return nil
+}
+
+// VolumeSettingsFromSettings returns the persisted volume settings, the defaults if they were never saved
+func VolumeSettingsFromSettings(s settings.Settings) (VolumeSettings, error) {
+	v := s.Volume
+	if v == (settings.Volume{}) {
+		return DefaultVolumeSettings(), nil
+	}
+	vs := VolumeSettings{
+		Day:        VolumeLimits{Min: v.DayMin, Max: v.DayMax, Curve: Curve(v.DayCurve)},
+		Night:      VolumeLimits{Min: v.NightMin, Max: v.NightMax, Curve: Curve(v.NightCurve)},
+		NightFrom:  v.NightFrom,
+		NightUntil: v.NightUntil,
+	}
+	return vs, vs.Validate()
+}
+
+// Settings returns the persisted form of the volume settings
+func (s VolumeSettings) Settings() settings.Volume {
+	return settings.Volume{
+		DayMin: s.Day.Min, DayMax: s.Day.Max, DayCurve: uint8(s.Day.Curve),
+		NightMin: s.Night.Min, NightMax: s.Night.Max, NightCurve: uint8(s.Night.Curve),
+		NightFrom: s.NightFrom, NightUntil: s.NightUntil,
+	}
+}
+
+// VolumePolicy remaps the knob of a VolumeGetter with the limits of the time of day
+type VolumePolicy struct {
+	getter   VolumeGetter
+	now      func() time.Time
+	settings VolumeSettings
+	night    bool
+
+	knob   int
+	offset int
+	last   int
+
+	adjusting bool
+	adjusted  bool
+}
+
+var _ VolumeGetter = &VolumePolicy{}
+
+func NewVolumePolicy(g VolumeGetter, s VolumeSettings, now func() time.Time) *VolumePolicy {
+	return &VolumePolicy{
+		getter:   g,
+		now:      now,
+		settings: s,
+		knob:     -1,
+		last:     -1,
+	}
+}
+
+// Get returns the remapped volume, it is updated when the knob moved, the limits changed or it was nudged.
+// Moving the knob drops the nudged steps.
+func (v *VolumePolicy) Get() (int, bool) {
+	knob, updated := v.getter.Get()
+	if updated && v.adjusting && v.knob >= 0 {
+		v.adjusted = true
+	}
+	if updated {
+		v.offset = 0
+	}
+	v.knob = knob
+
+	vol := v.volume(v.offset)
+	if v.adjusting {
+		vol = knob
+	}
+	if vol == v.last {
+		return vol, false
+	}
+	v.last = vol
+	return vol, true
+}
+
+// Nudge moves the volume by delta steps from the knob position, e.g. for volume keys. The volume stays within
+// the limits in effect.
+func (v *VolumePolicy) Nudge(delta int) {
+	if v.knob < 0 || v.adjusting {
+		return
+	}
+	v.offset = v.volume(v.offset+delta) - v.Limits().Map(v.knob)
+}
+
+// volume returns the volume of the knob moved by offset steps
+func (v *VolumePolicy) volume(offset int) int {
+	l := v.Limits()
+	vol := l.Map(v.knob)
+	if offset == 0 {
+		return vol
+	}
+	return max(int(l.Min), min(vol+offset, int(l.Max)))
+}
+
+// Limits returns the limits in effect
+func (v *VolumePolicy) Limits() VolumeLimits {
+	if v.Night() {
+		return v.settings.Night
+	}
+	return v.settings.Day
+}
+
+// Night returns whether the night limits are in effect, either by the clock or by SetNight
+func (v *VolumePolicy) Night() bool {
+	if v.night {
+		return true
+	}
+	from, until := int(v.settings.NightFrom), int(v.settings.NightUntil)
+	if from == until {
+		return false
+	}
+	h := v.now().Hour()
+	if from < until {
+		return from <= h && h < until
+	}
+	return h >= from || h < until
+}
+
+// SetNight forces the night limits regardless of the clock
+func (v *VolumePolicy) SetNight(enable bool) {
+	v.night = enable
+}
+
+func (v *VolumePolicy) Settings() VolumeSettings {
+	return v.settings
+}
+
+func (v *VolumePolicy) SetSettings(s VolumeSettings) {
+	v.settings = s
+}
+
+// StartAdjust passes the knob through unlimited, so a parent can pick the maximum volume
+func (v *VolumePolicy) StartAdjust() {
+	v.adjusting = true
+	v.adjusted = false
+}
+
+// FinishAdjust makes the knob position the maximum of the limits in effect, if it moved since StartAdjust.
+// The knob turned all the way down is ignored, a maximum of 0 would silence the box for good.
+// It returns whether the settings changed.
+func (v *VolumePolicy) FinishAdjust() bool {
+	if !v.adjusting {
+		return false
+	}
+	v.adjusting = false
+	if !v.adjusted || v.knob <= 0 {
+		return false
+	}
+	l := &v.settings.Day
+	if v.Night() {
+		l = &v.settings.Night
+	}
+	l.Max = uint8(v.knob)
+	if l.Min > l.Max {
+		l.Min = l.Max
+	}
+	return true
+}
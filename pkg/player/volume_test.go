@@ -0,0 +1,136 @@
+package player
+
+import (
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+)
+
+type knob struct {
+	v       int
+	updated bool
+}
+
+func (k *knob) Get() (int, bool) {
+	u := k.updated
+	k.updated = false
+	return k.v, u
+}
+
+func (k *knob) turn(v int) {
+	k.v = v
+	k.updated = true
+}
+
+func TestVolumeLimits_Map(t *testing.T) {
+	l := VolumeLimits{Min: 3, Max: 24, Curve: CurveLinear}
+	be.Equal(t, l.Map(0), 0)
+	be.Equal(t, l.Map(1), 4)
+	be.Equal(t, l.Map(15), 14)
+	be.Equal(t, l.Map(30), 24)
+
+	l.Curve = CurveQuadratic
+	be.Equal(t, l.Map(15), 9)
+	be.Equal(t, l.Map(30), 24)
+}
+
+func TestVolumePolicy_Night(t *testing.T) {
+	now := time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)
+	k := &knob{}
+	k.turn(30)
+	s := DefaultVolumeSettings()
+	s.NightFrom, s.NightUntil = 19, 7
+	v := NewVolumePolicy(k, s, func() time.Time { return now })
+
+	vol, updated := v.Get()
+	be.Equal(t, updated, true)
+	be.Equal(t, vol, 24)
+
+	// the night limits apply without touching the knob
+	now = now.Add(2 * time.Hour)
+	vol, updated = v.Get()
+	be.Equal(t, updated, true)
+	be.Equal(t, vol, 12)
+
+	now = now.Add(11 * time.Hour)
+	vol, _ = v.Get()
+	be.Equal(t, vol, 24)
+
+	v.SetNight(true)
+	vol, _ = v.Get()
+	be.Equal(t, vol, 12)
+}
+
+func TestVolumePolicy_Adjust(t *testing.T) {
+	now := time.Unix(0, 0)
+	k := &knob{}
+	k.turn(10)
+	v := NewVolumePolicy(k, DefaultVolumeSettings(), func() time.Time { return now })
+	v.Get()
+
+	// the knob position stays the maximum if it didn't move
+	v.StartAdjust()
+	be.Equal(t, v.FinishAdjust(), false)
+
+	v.StartAdjust()
+	k.turn(18)
+	vol, _ := v.Get()
+	be.Equal(t, vol, 18)
+	be.Equal(t, v.FinishAdjust(), true)
+	be.Equal(t, v.Settings().Day.Max, 18)
+
+	// all the way up is the new maximum
+	k.turn(30)
+	vol, _ = v.Get()
+	be.Equal(t, vol, 18)
+	be.NoError(t, v.Settings().Validate())
+
+	// all the way down would mute the box for good
+	v.StartAdjust()
+	k.turn(0)
+	v.Get()
+	be.Equal(t, v.FinishAdjust(), false)
+	be.Equal(t, v.Settings().Day.Max, 18)
+
+	// nor does a silent maximum load from the EEPROM
+	s := v.Settings()
+	s.Day.Min, s.Day.Max = 0, 0
+	be.AnError(t, s.Validate())
+}
+
+func TestVolumePolicy_Nudge(t *testing.T) {
+	now := time.Unix(0, 0)
+	k := &knob{}
+	k.turn(30)
+	v := NewVolumePolicy(k, DefaultVolumeSettings(), func() time.Time { return now })
+	vol, _ := v.Get()
+	be.Equal(t, vol, 24)
+
+	// the keys can't go past the limits
+	v.Nudge(1)
+	vol, updated := v.Get()
+	be.Equal(t, vol, 24)
+	be.Equal(t, updated, false)
+	v.Nudge(-1)
+	vol, updated = v.Get()
+	be.Equal(t, vol, 23)
+	be.Equal(t, updated, true)
+
+	v.SetNight(true)
+	v.Nudge(5)
+	vol, _ = v.Get()
+	be.Equal(t, vol, 12)
+
+	// turning the knob drops the steps
+	v.SetNight(false)
+	k.turn(15)
+	vol, _ = v.Get()
+	be.Equal(t, vol, 9)
+
+	// silence is left at the minimum
+	k.turn(0)
+	v.Get()
+	v.Nudge(1)
+	vol, _ = v.Get()
+	be.Equal(t, vol, 3)
+}
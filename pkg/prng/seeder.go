@@ -1,12 +1,9 @@
 package prng
 
 import (
-	"machine"
 	"trelligo/pkg/shims/rand"
 )
 
-var _ = Seeder(machine.GetRNG)
-
 type Seeder func() (uint32, error)
 
 func New(s Seeder) (*rand.Rand, error) {
@@ -22,7 +19,3 @@ func New(s Seeder) (*rand.Rand, error) {
 	rsrc := rand.NewSource(int64(hi)<<32 | int64(lo))
 	return rand.New(rsrc), nil
 }
-
-func NewDefault() (*rand.Rand, error) {
-	return New(machine.GetRNG)
-}
@@ -0,0 +1,20 @@
+//go:build !tinygo
+
+package prng
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"trelligo/pkg/shims/rand"
+)
+
+// hostRNG seeds from the operating system like machine.GetRNG does from the hardware
+func hostRNG() (uint32, error) {
+	var b [4]byte
+	_, err := crand.Read(b[:])
+	return binary.LittleEndian.Uint32(b[:]), err
+}
+
+func NewDefault() (*rand.Rand, error) {
+	return New(hostRNG)
+}
@@ -0,0 +1,14 @@
+//go:build tinygo
+
+package prng
+
+import (
+	"machine"
+	"trelligo/pkg/shims/rand"
+)
+
+var _ = Seeder(machine.GetRNG)
+
+func NewDefault() (*rand.Rand, error) {
+	return New(machine.GetRNG)
+}
@@ -0,0 +1,75 @@
+// Package eeprom reads and writes the emulated EEPROM of the seesaw, e.g. to keep settings across power cycles.
+package eeprom
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"time"
+	"trelligo/pkg/seesaw"
+)
+
+// Size is the number of usable bytes. The seesaw has 64 bytes, the last one holds the I2C address and is reserved.
+const Size = 63
+
+// maxChunk bytes per write, like for the NeoPixel buffer the seesaw can't take larger writes
+const maxChunk = 29
+
+// writeDelay the seesaw emulates the EEPROM in flash, which takes a while to write
+const writeDelay = 10 * time.Millisecond
+
+var ErrOutOfRange = errors.New("eeprom: access out of range")
+
+// Device implements io.ReaderAt and io.WriterAt
+type Device struct {
+	seesaw *seesaw.Device
+}
+
+var _ io.ReaderAt = &Device{}
+var _ io.WriterAt = &Device{}
+
+func New(dev *seesaw.Device) (*Device, error) {
+	err := dev.RequireModule(seesaw.ModuleEepromBase)
+	if err != nil {
+		return nil, err
+	}
+	return &Device{seesaw: dev}, nil
+}
+
+func (d *Device) ReadAt(p []byte, off int64) (int, error) {
+	if err := checkRange(len(p), off); err != nil {
+		return 0, err
+	}
+	err := d.seesaw.Read(seesaw.ModuleEepromBase, seesaw.FunctionAddress(off), p)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (d *Device) WriteAt(p []byte, off int64) (int, error) {
+	if err := checkRange(len(p), off); err != nil {
+		return 0, err
+	}
+	n := 0
+	for n < len(p) {
+		end := n + maxChunk
+		if end > len(p) {
+			end = len(p)
+		}
+		err := d.seesaw.Write(seesaw.ModuleEepromBase, seesaw.FunctionAddress(off+int64(n)), p[n:end])
+		if err != nil {
+			return n, errors.New("failed to write eeprom at " + strconv.Itoa(int(off)+n) + ": " + err.Error())
+		}
+		time.Sleep(writeDelay)
+		n = end
+	}
+	return n, nil
+}
+
+func checkRange(n int, off int64) error {
+	if off < 0 || off+int64(n) > Size {
+		return ErrOutOfRange
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package eeprom
+
+import (
+	"testing"
+	"trelligo/pkg/be"
+	"trelligo/pkg/seesaw"
+	"trelligo/pkg/seesaw/seesawsim"
+)
+
+func TestDevice_ReadWrite(t *testing.T) {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	e, err := New(seesaw.New(seesaw.DefaultSeesawAddress, sim))
+	be.NoError(t, err)
+
+	data := make([]byte, 40)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	n, err := e.WriteAt(data, 10)
+	be.NoError(t, err)
+	be.Equal(t, n, 40)
+	be.Equal(t, sim.EEPROM()[49], 39)
+
+	buf := make([]byte, 4)
+	_, err = e.ReadAt(buf, 48)
+	be.NoError(t, err)
+	be.Equal(t, string(buf), string([]byte{38, 39, 0xFF, 0xFF}))
+
+	// the I2C address is off limits
+	_, err = e.WriteAt([]byte{1}, Size)
+	be.Equal(t, err, ErrOutOfRange)
+}
@@ -0,0 +1,8 @@
+//go:build tinygo
+
+package seesaw
+
+import "machine"
+
+// assert the machine.I2C conforms to our interface
+var _ = I2C(&machine.I2C{})
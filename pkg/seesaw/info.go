@@ -0,0 +1,117 @@
+package seesaw
+
+import (
+	"errors"
+	"strconv"
+)
+
+// DateCode is the firmware build date as encoded in the version register
+type DateCode uint16
+
+// Year returns the year of the firmware build, e.g. 2023
+func (c DateCode) Year() int {
+	return 2000 + int(c&0x3F)
+}
+
+// Month returns the month of the firmware build, 1 to 12
+func (c DateCode) Month() int {
+	return int(c>>7) & 0x0F
+}
+
+// Day returns the day of the firmware build, 1 to 31
+func (c DateCode) Day() int {
+	return int(c>>11) & 0x1F
+}
+
+// Options is the bitmask of modules compiled into the firmware, one bit per ModuleBaseAddress
+type Options uint32
+
+// Has returns whether the module is available in the firmware
+func (o Options) Has(module ModuleBaseAddress) bool {
+	return o&(1<<module) != 0
+}
+
+// Info describes the seesaw chip and its firmware
+type Info struct {
+	HardwareID  byte
+	ProductCode uint16
+	DateCode    DateCode
+	Options     Options
+
+	// Temperature is the die temperature in degrees Celsius
+	Temperature float32
+}
+
+// Info reads the firmware version, compiled-in modules and die temperature of the device
+func (d *Device) Info() (Info, error) {
+	info := Info{HardwareID: d.hwid}
+
+	version, err := d.readUint32(FunctionStatusVersion)
+	if err != nil {
+		return info, errors.New("failed to read version: " + err.Error())
+	}
+	info.ProductCode = uint16(version >> 16)
+	info.DateCode = DateCode(version)
+
+	info.Options, err = d.Options()
+	if err != nil {
+		return info, err
+	}
+
+	info.Temperature, err = d.Temperature()
+	if err != nil {
+		return info, err
+	}
+
+	return info, nil
+}
+
+// Options reads the bitmask of modules compiled into the firmware. The value is cached until the next reset.
+func (d *Device) Options() (Options, error) {
+	if d.optionsRead {
+		return d.options, nil
+	}
+
+	opts, err := d.readUint32(FunctionStatusOptions)
+	if err != nil {
+		return 0, errors.New("failed to read options: " + err.Error())
+	}
+	d.options = Options(opts)
+	d.optionsRead = true
+	return d.options, nil
+}
+
+// RequireModule returns an error if the firmware of the device was compiled without the given module. Drivers
+// should check this before using a module, the seesaw otherwise just NACKs or returns garbage.
+func (d *Device) RequireModule(module ModuleBaseAddress) error {
+	opts, err := d.Options()
+	if err != nil {
+		return err
+	}
+	if !opts.Has(module) {
+		return errors.New("firmware does not support module 0x" + byteToHexString(byte(module)) +
+			", options: 0x" + strconv.FormatUint(uint64(opts), 16))
+	}
+	return nil
+}
+
+// Temperature reads the die temperature in degrees Celsius
+func (d *Device) Temperature() (float32, error) {
+	raw, err := d.readUint32(FunctionStatusTemp)
+	if err != nil {
+		return 0, errors.New("failed to read temperature: " + err.Error())
+	}
+
+	// 16.16 fixed point, the upper two bits are not part of the value
+	raw &= 0x3FFFFFFF
+	return float32(raw) / (1 << 16), nil
+}
+
+func (d *Device) readUint32(function FunctionAddress) (uint32, error) {
+	buf := make([]byte, 4)
+	err := d.Read(ModuleStatusBase, function, buf)
+	if err != nil {
+		return 0, err
+	}
+	return uint32(buf[0])<<24 | uint32(buf[1])<<16 | uint32(buf[2])<<8 | uint32(buf[3]), nil
+}
@@ -0,0 +1,35 @@
+package seesaw_test
+
+import (
+	"testing"
+	"trelligo/pkg/be"
+	"trelligo/pkg/seesaw"
+	"trelligo/pkg/seesaw/seesawsim"
+)
+
+func TestDevice_Info(t *testing.T) {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	dev := seesaw.New(seesaw.DefaultSeesawAddress, sim)
+	be.NoError(t, dev.SoftReset())
+
+	info, err := dev.Info()
+	be.NoError(t, err)
+
+	be.Equal(t, info.HardwareID, seesaw.HwIdCodeSAMD09)
+	be.Equal(t, info.ProductCode, 3954)
+	be.Equal(t, info.DateCode.Year(), 2023)
+	be.Equal(t, info.DateCode.Month(), 6)
+	be.Equal(t, info.DateCode.Day(), 14)
+	be.Equal(t, info.Options.Has(seesaw.ModuleKeypadBase), true)
+	be.Equal(t, info.Options.Has(seesaw.ModuleSpectrumBase), false)
+	be.Equal(t, info.Temperature, 25)
+}
+
+func TestDevice_RequireModule(t *testing.T) {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	sim.SetOptions(1 << seesaw.ModuleNeoPixelBase)
+	dev := seesaw.New(seesaw.DefaultSeesawAddress, sim)
+
+	be.NoError(t, dev.RequireModule(seesaw.ModuleNeoPixelBase))
+	be.AnError(t, dev.RequireModule(seesaw.ModuleKeypadBase))
+}
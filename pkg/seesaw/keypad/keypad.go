@@ -1,7 +1,6 @@
 package keypad
 
 import (
-	"time"
 	"trelligo/pkg/seesaw"
 	"unsafe"
 )
@@ -30,15 +29,21 @@ type SeesawKeypad struct {
 	seesaw *seesaw.Device
 }
 
-func New(dev *seesaw.Device) *SeesawKeypad {
-	return &SeesawKeypad{seesaw: dev}
+func New(dev *seesaw.Device) (*SeesawKeypad, error) {
+	err := dev.RequireModule(seesaw.ModuleKeypadBase)
+	if err != nil {
+		return nil, err
+	}
+	return &SeesawKeypad{seesaw: dev}, nil
 }
 
 // KeyEventCount returns the number of pending KeyEvent s in the FIFO queue
 func (s *SeesawKeypad) KeyEventCount() (uint8, error) {
 	//https://github.com/adafruit/Adafruit_Seesaw/blob/master/Adafruit_seesaw.cpp#L721
+	// not adapted to the seesaw's timing: an empty FIFO is NACKed, which would look like a too short delay
 	buf := make([]byte, 1)
-	err := s.seesaw.Read(seesaw.ModuleKeypadBase, seesaw.FunctionKeypadCount, buf, 500*time.Microsecond)
+	delay := s.seesaw.ReadDelay(seesaw.ModuleKeypadBase, seesaw.FunctionKeypadCount)
+	err := s.seesaw.ReadWithDelay(seesaw.ModuleKeypadBase, seesaw.FunctionKeypadCount, buf, delay)
 	return buf[0], err
 }
 
@@ -57,7 +62,7 @@ func (s *SeesawKeypad) Read(buf []KeyEvent) error {
 	// use some unsafe magic to avoid copy-ing the entire buffer
 	bytesBuf := *(*[]byte)(unsafe.Pointer(&buf))
 
-	return s.seesaw.Read(seesaw.ModuleKeypadBase, seesaw.FunctionKeypadFifo, bytesBuf, 2*time.Millisecond)
+	return s.seesaw.Read(seesaw.ModuleKeypadBase, seesaw.FunctionKeypadFifo, bytesBuf)
 }
 
 // ConfigureKeypad enables or disables a key and edge on the keypad module
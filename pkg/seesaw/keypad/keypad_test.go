@@ -0,0 +1,90 @@
+package keypad
+
+import (
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/seesaw"
+	"trelligo/pkg/seesaw/seesawsim"
+)
+
+func newTestKeypad(t *testing.T) (*SeesawKeypad, *seesawsim.Device) {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	kpd, err := New(seesaw.New(seesaw.DefaultSeesawAddress, sim))
+	be.NoError(t, err)
+	return kpd, sim
+}
+
+func TestNew_MissingModule(t *testing.T) {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	sim.SetOptions(1 << seesaw.ModuleNeoPixelBase)
+
+	_, err := New(seesaw.New(seesaw.DefaultSeesawAddress, sim))
+	be.AnError(t, err)
+}
+
+func TestSeesawKeypad_ConfigureKeypad(t *testing.T) {
+	kpd, sim := newTestKeypad(t)
+
+	err := kpd.ConfigureKeypad(9, EdgeRising, true)
+	be.NoError(t, err)
+	be.Equal(t, sim.KeyEventEnabled(9, uint8(EdgeRising)), true)
+	be.Equal(t, sim.KeyEventEnabled(9, uint8(EdgeFalling)), false)
+
+	err = kpd.ConfigureKeypad(9, EdgeRising, false)
+	be.NoError(t, err)
+	be.Equal(t, sim.KeyEventEnabled(9, uint8(EdgeRising)), false)
+}
+
+func TestSeesawKeypad_Read(t *testing.T) {
+	kpd, sim := newTestKeypad(t)
+
+	be.NoError(t, kpd.ConfigureKeypad(9, EdgeRising, true))
+	be.NoError(t, kpd.ConfigureKeypad(9, EdgeFalling, true))
+
+	sim.Press(9)
+	sim.Release(9)
+	// not enabled, must not show up
+	sim.Press(10)
+
+	n, err := kpd.KeyEventCount()
+	be.NoError(t, err)
+	be.Equal(t, n, 2)
+
+	buf := make([]KeyEvent, n)
+	err = kpd.Read(buf)
+	be.NoError(t, err)
+
+	be.Equal(t, buf[0].Key(), 9)
+	be.Equal(t, buf[0].Edge(), EdgeRising)
+	be.Equal(t, buf[1].Key(), 9)
+	be.Equal(t, buf[1].Edge(), EdgeFalling)
+	be.Equal(t, sim.PendingKeyEvents(), 0)
+}
+
+func TestSeesawKeypad_SetKeypadInterrupt(t *testing.T) {
+	kpd, sim := newTestKeypad(t)
+
+	be.NoError(t, kpd.SetKeypadInterrupt(true))
+	be.Equal(t, sim.KeypadInterruptEnabled(), true)
+
+	be.NoError(t, kpd.SetKeypadInterrupt(false))
+	be.Equal(t, sim.KeypadInterruptEnabled(), false)
+}
+
+func TestSeesawKeypad_KeyEventCount_Calibrated(t *testing.T) {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	sim.SetResponseDelay(5 * time.Millisecond)
+	dev := seesaw.New(seesaw.DefaultSeesawAddress, sim)
+	_, err := dev.Calibrate(3)
+	be.NoError(t, err)
+	kpd, err := New(dev)
+	be.NoError(t, err)
+
+	// the count is read no faster than calibrated
+	be.NoError(t, kpd.ConfigureKeypad(9, EdgeRising, true))
+	sim.Press(9)
+	n, err := kpd.KeyEventCount()
+	be.NoError(t, err)
+	be.Equal(t, n, 1)
+}
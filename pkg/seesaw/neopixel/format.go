@@ -0,0 +1,91 @@
+package neopixel
+
+// PixelFormat is the order in which the NeoPixels expect their color channels, with or without a white channel
+type PixelFormat uint8
+
+const (
+	FormatGRB PixelFormat = iota
+	FormatRGB
+	FormatBRG
+	FormatRBG
+	FormatGBR
+	FormatBGR
+	FormatGRBW
+	FormatRGBW
+	FormatWRGB
+	FormatBGRW
+)
+
+// channelOrder holds the byte position of each channel within an encoded pixel, w is -1 for pixels without white
+type channelOrder struct {
+	r, g, b, w int8
+}
+
+var channelOrders = [...]channelOrder{
+	FormatGRB:  {r: 1, g: 0, b: 2, w: -1},
+	FormatRGB:  {r: 0, g: 1, b: 2, w: -1},
+	FormatBRG:  {r: 1, g: 2, b: 0, w: -1},
+	FormatRBG:  {r: 0, g: 2, b: 1, w: -1},
+	FormatGBR:  {r: 2, g: 0, b: 1, w: -1},
+	FormatBGR:  {r: 2, g: 1, b: 0, w: -1},
+	FormatGRBW: {r: 1, g: 0, b: 2, w: 3},
+	FormatRGBW: {r: 0, g: 1, b: 2, w: 3},
+	FormatWRGB: {r: 1, g: 2, b: 3, w: 0},
+	FormatBGRW: {r: 2, g: 1, b: 0, w: 3},
+}
+
+// Valid returns whether the format is known
+func (f PixelFormat) Valid() bool {
+	return int(f) < len(channelOrders)
+}
+
+// HasWhite returns whether the pixels have a white channel
+func (f PixelFormat) HasWhite() bool {
+	return channelOrders[f].w >= 0
+}
+
+// BytesPerPixel returns the number of bytes per pixel in the NeoPixel buffer
+func (f PixelFormat) BytesPerPixel() int {
+	if f.HasWhite() {
+		return 4
+	}
+	return 3
+}
+
+// MaxPixels returns the number of pixels that fit the seesaw's NeoPixel buffer. The seesaw has built in NeoPixel
+// support for up to 170 RGB or 127 RGBW pixels. Note: older firmware is limited to 63 pixels max.
+func (f PixelFormat) MaxPixels() int {
+	if f.HasWhite() {
+		return 127
+	}
+	return 170
+}
+
+// put encodes the color into buf and returns the number of bytes written
+func (f PixelFormat) put(buf []byte, color RGBW) int {
+	o := channelOrders[f]
+	buf[o.r] = color.R
+	buf[o.g] = color.G
+	buf[o.b] = color.B
+	if o.w < 0 {
+		return 3
+	}
+	buf[o.w] = color.W
+	return 4
+}
+
+// Speed is the frequency of the NeoPixel data signal, the zero value is the 800kHz most NeoPixels use
+type Speed uint8
+
+const (
+	Speed800kHz Speed = iota
+	Speed400kHz
+)
+
+// register returns the value of the seesaw speed register, which is 0 for 400kHz and 1 for 800kHz
+func (s Speed) register() byte {
+	if s == Speed400kHz {
+		return 0
+	}
+	return 1
+}
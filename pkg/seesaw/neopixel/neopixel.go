@@ -11,44 +11,97 @@ import (
 // this is an empirically determined delay that seems to have good results
 const seesawWriteDelay = time.Millisecond * 50
 
-const encodedColorLength = 3
+// the seesaw can at most deal with 30 bytes according to the datasheet, but
+// crashes after 29 bytes. So we only send 29 data bytes at a time
+const chunkSize = 29
+
+// mergeGap unchanged bytes between two changed ranges are sent anyway if there are at most this many, a separate
+// buffer write costs 4 bytes of overhead
+const mergeGap = 4
 
 // RGBW represents the RGBW color of an LED.
 type RGBW struct {
 	R, G, B, W uint8
 }
 
+// Config describes the NeoPixels connected to the seesaw
+type Config struct {
+	Pin    uint8
+	Count  int
+	Format PixelFormat
+	Speed  Speed
+}
+
 type Device struct {
 	seesaw          *seesaw.Device
 	ledCount        int
 	pin             uint8
+	format          PixelFormat
+	speed           Speed
 	lastOperationAt time.Time
+
+	// committed is the NeoPixel buffer as last written to the seesaw once synced, dirty whether it was changed since
+	// the last ShowPixels
+	committed []byte
+	synced    bool
+	frame     []byte
+	dirty     bool
 }
 
+// New sets up GRB NeoPixels at 800kHz, the most common kind and the one on the NeoTrellis
 func New(dev *seesaw.Device, pin uint8, ledCount int) (*Device, error) {
+	return NewWithConfig(dev, Config{
+		Pin:    pin,
+		Count:  ledCount,
+		Format: FormatGRB,
+		Speed:  Speed800kHz,
+	})
+}
+
+func NewWithConfig(dev *seesaw.Device, cfg Config) (*Device, error) {
+
+	err := dev.RequireModule(seesaw.ModuleNeoPixelBase)
+	if err != nil {
+		return nil, err
+	}
+
+	if !cfg.Format.Valid() {
+		return nil, errors.New("invalid pixel format: " + strconv.Itoa(int(cfg.Format)))
+	}
 
 	pixel := &Device{
 		seesaw:   dev,
-		ledCount: ledCount,
-		pin:      pin,
+		ledCount: cfg.Count,
+		pin:      cfg.Pin,
+		format:   cfg.Format,
+		speed:    cfg.Speed,
 	}
 
-	if !pixel.checkBufferLength(ledCount) {
-		return nil, errors.New("invalid pixel count: " + strconv.Itoa(ledCount))
+	if !pixel.checkBufferLength(cfg.Count) {
+		return nil, errors.New("invalid pixel count: " + strconv.Itoa(cfg.Count))
 	}
+	pixel.committed = make([]byte, pixel.calculateBufferLength(cfg.Count))
+	pixel.frame = make([]byte, pixel.calculateBufferLength(cfg.Count))
 
 	time.Sleep(seesawWriteDelay)
 
-	err := pixel.setupPin()
+	err = pixel.setupPin()
 	if err != nil {
-		return nil, errors.New("failed to update pixel pin " + strconv.Itoa(int(pin)) + ": " + err.Error())
+		return nil, errors.New("failed to update pixel pin " + strconv.Itoa(int(cfg.Pin)) + ": " + err.Error())
+	}
+
+	time.Sleep(seesawWriteDelay)
+
+	err = pixel.setupSpeed()
+	if err != nil {
+		return nil, errors.New("failed to update pixel speed: " + err.Error())
 	}
 
 	time.Sleep(seesawWriteDelay)
 
 	err = pixel.setupLedCount()
 	if err != nil {
-		return nil, errors.New("failed to update pixel count " + strconv.Itoa(ledCount) + ": " + err.Error())
+		return nil, errors.New("failed to update pixel count " + strconv.Itoa(cfg.Count) + ": " + err.Error())
 	}
 
 	time.Sleep(seesawWriteDelay)
@@ -58,56 +111,112 @@ func New(dev *seesaw.Device, pin uint8, ledCount int) (*Device, error) {
 
 func (s *Device) setupLedCount() error {
 
-	lenBytes := calculateBufferLength(s.ledCount)
+	lenBytes := s.calculateBufferLength(s.ledCount)
 	buf := []byte{byte(lenBytes >> 8), byte(lenBytes & 0xFF)}
 	return s.seesaw.Write(seesaw.ModuleNeoPixelBase, seesaw.FunctionNeopixelBufLength, buf)
 }
 
-func calculateBufferLength(ledCount int) int {
-	return ledCount * encodedColorLength
+func (s *Device) calculateBufferLength(ledCount int) int {
+	return ledCount * s.format.BytesPerPixel()
 }
 
 func (s *Device) setupPin() error {
 	return s.seesaw.WriteRegister(seesaw.ModuleNeoPixelBase, seesaw.FunctionNeopixelPin, s.pin)
 }
 
+func (s *Device) setupSpeed() error {
+	return s.seesaw.WriteRegister(seesaw.ModuleNeoPixelBase, seesaw.FunctionNeopixelSpeed, s.speed.register())
+}
+
 // WriteColorAtOffset updates the color for a single LED at the given offset
 func (s *Device) WriteColorAtOffset(offset uint16, color RGBW) error {
 
-	buf := make([]byte, encodedColorLength)
-	putGRB(buf, color)
-	byteOffset := offset * uint16(encodedColorLength)
+	buf := make([]byte, s.format.BytesPerPixel())
+	s.format.put(buf, color)
+	byteOffset := offset * uint16(s.format.BytesPerPixel())
 	return s.writeBuffer(byteOffset, buf)
 }
 
+// Render writes the given colors like WriteColors and shows them, but only sends the bytes that changed since the
+// last write. If nothing changed, nothing is sent at all.
+func (s *Device) Render(buf []RGBW) error {
+	if len(buf) > s.ledCount {
+		return errors.New("buffer too big " + strconv.Itoa(len(buf)) + ">" + strconv.Itoa(s.ledCount))
+	}
+
+	frame := s.frame[:s.format.BytesPerPixel()*len(buf)]
+	pos := 0
+	for _, c := range buf {
+		pos += s.format.put(frame[pos:], c)
+	}
+
+	for start := 0; start < len(frame); {
+		if s.unchanged(frame, start) {
+			start++
+			continue
+		}
+
+		// extend the range until there are more than mergeGap unchanged bytes
+		end, gap := start+1, 0
+		for i := end; i < len(frame) && gap <= mergeGap; i++ {
+			if s.unchanged(frame, i) {
+				gap++
+			} else {
+				end, gap = i+1, 0
+			}
+		}
+
+		err := s.writeChunked(start, frame[start:end])
+		if err != nil {
+			return err
+		}
+		start = end
+	}
+	s.synced = s.synced || len(buf) == s.ledCount
+
+	if !s.dirty {
+		return nil
+	}
+	return s.ShowPixels()
+}
+
+// unchanged whether the byte at i is known to be in the seesaw's buffer already
+func (s *Device) unchanged(frame []byte, i int) bool {
+	return s.synced && frame[i] == s.committed[i]
+}
+
 // WriteColors writes the given colors to the seesaws NeoPixel buffer
 func (s *Device) WriteColors(buf []RGBW) error {
 
 	if len(buf) > s.ledCount {
-		return errors.New("buffer too big " + strconv.Itoa(len(buf)) + ">" + strconv.Itoa(s.ledCount*encodedColorLength))
+		return errors.New("buffer too big " + strconv.Itoa(len(buf)) + ">" + strconv.Itoa(s.ledCount))
 	}
 
-	tx := make([]byte, encodedColorLength*len(buf))
+	tx := make([]byte, s.format.BytesPerPixel()*len(buf))
 	pos := 0
 	for _, c := range buf {
 		w := tx[pos:]
-		n := putGRB(w, c)
+		n := s.format.put(w, c)
 		pos += n
 	}
 
-	// the seesaw can at most deal with 30 bytes according to the datasheet, but
-	// crashes after 29 bytes. So we only send 29 data bytes at a time
-	const chunkSize = 29
+	err := s.writeChunked(0, tx)
+	if err != nil {
+		return err
+	}
+	s.synced = s.synced || len(buf) == s.ledCount
+	return nil
+}
 
-	// write the data chunk-by-chunk
-	for i := 0; i < len(tx); i += chunkSize {
-		toSend := tx[i:min(i+chunkSize, len(tx))]
-		err := s.writeBuffer(uint16(i), toSend)
+// writeChunked writes the data chunk-by-chunk starting at the given byte offset
+func (s *Device) writeChunked(byteOffset int, data []byte) error {
+	for i := 0; i < len(data); i += chunkSize {
+		toSend := data[i:min(i+chunkSize, len(data))]
+		err := s.writeBuffer(uint16(byteOffset+i), toSend)
 		if err != nil {
-			return errors.New("failed to write NeoPixel buffer offset " + strconv.Itoa(i) + ": " + err.Error())
+			return errors.New("failed to write NeoPixel buffer offset " + strconv.Itoa(byteOffset+i) + ": " + err.Error())
 		}
 	}
-
 	return nil
 }
 
@@ -116,7 +225,17 @@ func (s *Device) writeBuffer(byteOffset uint16, buf []byte) error {
 	tx[0] = uint8(byteOffset >> 8)
 	tx[1] = uint8(byteOffset)
 	copy(tx[2:], buf)
-	return s.seesaw.Write(seesaw.ModuleNeoPixelBase, seesaw.FunctionNeopixelBuf, tx)
+	err := s.seesaw.Write(seesaw.ModuleNeoPixelBase, seesaw.FunctionNeopixelBuf, tx)
+	s.lastOperationAt = time.Now()
+	if err != nil {
+		return err
+	}
+
+	if int(byteOffset) < len(s.committed) {
+		copy(s.committed[byteOffset:], buf)
+	}
+	s.dirty = true
+	return nil
 }
 
 func (s *Device) ShowPixels() error {
@@ -125,7 +244,13 @@ func (s *Device) ShowPixels() error {
 	// https://github.com/adafruit/Adafruit_Seesaw/blob/8a2dc5e0645239cb34e23a4b62c456436b098ab3/seesaw_neopixel.cpp#L109
 	s.waitSinceLastOperation(time.Microsecond * 300)
 
-	return s.seesaw.Write(seesaw.ModuleNeoPixelBase, seesaw.FunctionNeopixelShow, nil)
+	err := s.seesaw.Write(seesaw.ModuleNeoPixelBase, seesaw.FunctionNeopixelShow, nil)
+	s.lastOperationAt = time.Now()
+	if err != nil {
+		return err
+	}
+	s.dirty = false
+	return nil
 }
 
 func (s *Device) waitSinceLastOperation(d time.Duration) {
@@ -136,13 +261,10 @@ func (s *Device) waitSinceLastOperation(d time.Duration) {
 	}
 }
 
-// checkBufferLength checks whether the length is supported by seesaw. This depends on the pixel type.
-// The seesaw has built in NeoPixel support for up to 170 RGB or 127 RGBW pixels. The
-// output pin as well as the communication protocol frequency are configurable. Note:
-// older firmware is limited to 63 pixels max.
+// checkBufferLength checks whether the length is supported by seesaw. This depends on the pixel type, see
+// PixelFormat.MaxPixels.
 func (s *Device) checkBufferLength(l int) bool {
-	const maxRgbPixelCount = 170
-	return l <= maxRgbPixelCount
+	return l >= 0 && l <= s.format.MaxPixels()
 }
 
 func min(a, b int) int {
@@ -151,10 +273,3 @@ func min(a, b int) int {
 	}
 	return b
 }
-
-func putGRB(buf []byte, color RGBW) int {
-	buf[0] = color.G
-	buf[1] = color.R
-	buf[2] = color.B
-	return encodedColorLength
-}
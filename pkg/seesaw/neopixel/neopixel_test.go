@@ -0,0 +1,159 @@
+package neopixel
+
+import (
+	"testing"
+	"trelligo/pkg/be"
+	"trelligo/pkg/seesaw"
+	"trelligo/pkg/seesaw/seesawsim"
+)
+
+func newTestDevice(t *testing.T, ledCount int) (*Device, *seesawsim.Device) {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	pix, err := New(seesaw.New(seesaw.DefaultSeesawAddress, sim), 3, ledCount)
+	be.NoError(t, err)
+	return pix, sim
+}
+
+func TestNew(t *testing.T) {
+	_, sim := newTestDevice(t, 16)
+
+	be.Equal(t, sim.PixelPin(), 3)
+	be.Equal(t, len(sim.PixelBuffer()), 16*3)
+}
+
+func TestNew_TooManyPixels(t *testing.T) {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	_, err := New(seesaw.New(seesaw.DefaultSeesawAddress, sim), 3, 171)
+	be.AnError(t, err)
+}
+
+func TestDevice_WriteColors(t *testing.T) {
+	pix, sim := newTestDevice(t, 16)
+
+	colors := make([]RGBW, 16)
+	for i := range colors {
+		colors[i] = RGBW{R: uint8(i), G: uint8(i + 100), B: uint8(i + 200)}
+	}
+
+	err := pix.WriteColors(colors)
+	be.NoError(t, err)
+	be.Equal(t, sim.Shows(), 0)
+
+	err = pix.ShowPixels()
+	be.NoError(t, err)
+	be.Equal(t, sim.Shows(), 1)
+
+	shown := sim.ShownPixels()
+	for i, c := range colors {
+		be.Equal(t, shown[i*3], c.G)
+		be.Equal(t, shown[i*3+1], c.R)
+		be.Equal(t, shown[i*3+2], c.B)
+	}
+}
+
+func TestDevice_WriteColorAtOffset(t *testing.T) {
+	pix, sim := newTestDevice(t, 16)
+
+	err := pix.WriteColorAtOffset(5, RGBW{R: 1, G: 2, B: 3})
+	be.NoError(t, err)
+
+	buf := sim.PixelBuffer()
+	be.Equal(t, buf[15], 2)
+	be.Equal(t, buf[16], 1)
+	be.Equal(t, buf[17], 3)
+}
+
+func TestDevice_Render(t *testing.T) {
+	pix, sim := newTestDevice(t, 16)
+
+	colors := make([]RGBW, 16)
+	for i := range colors {
+		colors[i] = RGBW{R: 10, G: 20, B: 30}
+	}
+
+	// first render sends everything: 48 bytes in two chunks, plus show
+	before := sim.Transactions()
+	be.NoError(t, pix.Render(colors))
+	be.Equal(t, sim.Transactions()-before, 3)
+	be.Equal(t, sim.Shows(), 1)
+
+	// nothing changed, nothing sent
+	before = sim.Transactions()
+	be.NoError(t, pix.Render(colors))
+	be.Equal(t, sim.Transactions(), before)
+	be.Equal(t, sim.Shows(), 1)
+
+	// two nearby pixels changed, one write and show
+	colors[3].R = 11
+	colors[4].B = 31
+	before = sim.Transactions()
+	be.NoError(t, pix.Render(colors))
+	be.Equal(t, sim.Transactions()-before, 2)
+	be.Equal(t, sim.Shows(), 2)
+
+	shown := sim.ShownPixels()
+	be.Equal(t, shown[3*3+1], 11)
+	be.Equal(t, shown[4*3+2], 31)
+	be.Equal(t, shown[15*3], 20)
+
+	// pixels far apart are written separately
+	colors[0].G = 21
+	colors[15].G = 21
+	before = sim.Transactions()
+	be.NoError(t, pix.Render(colors))
+	be.Equal(t, sim.Transactions()-before, 3)
+}
+
+func TestNewWithConfig_DefaultSpeed(t *testing.T) {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	_, err := NewWithConfig(seesaw.New(seesaw.DefaultSeesawAddress, sim), Config{Pin: 3, Count: 16})
+	be.NoError(t, err)
+	// the zero value runs at 800kHz, the seesaw register calls that 1
+	be.Equal(t, sim.PixelSpeed(), 1)
+}
+
+func TestNewWithConfig_RGBW(t *testing.T) {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	pix, err := NewWithConfig(seesaw.New(seesaw.DefaultSeesawAddress, sim), Config{
+		Pin:    5,
+		Count:  10,
+		Format: FormatRGBW,
+		Speed:  Speed400kHz,
+	})
+	be.NoError(t, err)
+	be.Equal(t, sim.PixelSpeed(), 0)
+	be.Equal(t, len(sim.PixelBuffer()), 40)
+
+	be.NoError(t, pix.WriteColorAtOffset(2, RGBW{R: 1, G: 2, B: 3, W: 4}))
+	buf := sim.PixelBuffer()
+	be.Equal(t, buf[8], 1)
+	be.Equal(t, buf[9], 2)
+	be.Equal(t, buf[10], 3)
+	be.Equal(t, buf[11], 4)
+}
+
+func TestNewWithConfig_MaxPixels(t *testing.T) {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	dev := seesaw.New(seesaw.DefaultSeesawAddress, sim)
+
+	_, err := NewWithConfig(dev, Config{Count: 128, Format: FormatGRBW})
+	be.AnError(t, err)
+
+	_, err = NewWithConfig(dev, Config{Count: 127, Format: FormatGRBW})
+	be.NoError(t, err)
+	be.Equal(t, len(sim.PixelBuffer()), 127*4)
+}
+
+func TestPixelFormat_Put(t *testing.T) {
+	c := RGBW{R: 1, G: 2, B: 3, W: 4}
+	buf := make([]byte, 4)
+
+	be.Equal(t, FormatGRB.put(buf, c), 3)
+	be.Equal(t, string(buf[:3]), string([]byte{2, 1, 3}))
+
+	be.Equal(t, FormatBGR.put(buf, c), 3)
+	be.Equal(t, string(buf[:3]), string([]byte{3, 2, 1}))
+
+	be.Equal(t, FormatWRGB.put(buf, c), 4)
+	be.Equal(t, string(buf), string([]byte{4, 1, 2, 3}))
+}
@@ -0,0 +1,57 @@
+// Package pwm drives the PWM outputs of the seesaw timer module.
+package pwm
+
+import (
+	"errors"
+	"strconv"
+	"trelligo/pkg/seesaw"
+)
+
+// MaxDutyCycle is the duty cycle of an output that is always on
+const MaxDutyCycle = 0xFFFF
+
+type Device struct {
+	seesaw *seesaw.Device
+}
+
+func New(dev *seesaw.Device) (*Device, error) {
+	err := dev.RequireModule(seesaw.ModuleTimerBase)
+	if err != nil {
+		return nil, err
+	}
+	return &Device{seesaw: dev}, nil
+}
+
+// SetDutyCycle sets the 16bit duty cycle of a PWM pin, 0 is always off and MaxDutyCycle is always on
+func (d *Device) SetDutyCycle(pin uint8, duty uint16) error {
+	//https://github.com/adafruit/Adafruit_Seesaw/blob/master/Adafruit_seesaw.cpp#L361
+	ch, err := d.channel(pin)
+	if err != nil {
+		return err
+	}
+	return d.seesaw.Write(seesaw.ModuleTimerBase, seesaw.FunctionTimerPwm, []byte{ch, byte(duty >> 8), byte(duty)})
+}
+
+// SetFrequency sets the PWM frequency of a pin in Hz. Note that on the SAMD09 pins sharing a timer also share
+// the frequency.
+func (d *Device) SetFrequency(pin uint8, freq uint16) error {
+	ch, err := d.channel(pin)
+	if err != nil {
+		return err
+	}
+	return d.seesaw.Write(seesaw.ModuleTimerBase, seesaw.FunctionTimerFreq, []byte{ch, byte(freq >> 8), byte(freq)})
+}
+
+// channel maps a pin to the PWM channel of the timer module. The SAMD09 firmware numbers its four PWM outputs,
+// the ATtiny firmware simply uses the pin number.
+func (d *Device) channel(pin uint8) (uint8, error) {
+	if d.seesaw.HardwareID() != seesaw.HwIdCodeSAMD09 {
+		return pin, nil
+	}
+
+	// PWM_0_PIN to PWM_3_PIN of the SAMD09 breakout
+	if pin < 4 || pin > 7 {
+		return 0, errors.New("pin does not support PWM: " + strconv.Itoa(int(pin)))
+	}
+	return pin - 4, nil
+}
@@ -0,0 +1,34 @@
+package pwm
+
+import (
+	"testing"
+	"trelligo/pkg/be"
+	"trelligo/pkg/seesaw"
+	"trelligo/pkg/seesaw/seesawsim"
+)
+
+func TestDevice_SetDutyCycle(t *testing.T) {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	dev := seesaw.New(seesaw.DefaultSeesawAddress, sim)
+	// the reset reads the hardware ID
+	be.NoError(t, dev.SoftReset())
+	d, err := New(dev)
+	be.NoError(t, err)
+
+	// the SAMD09 numbers its PWM pins 4 to 7 as channels 0 to 3
+	be.NoError(t, d.SetDutyCycle(5, 0x8000))
+	be.Equal(t, sim.DutyCycle(1), 0x8000)
+	be.NoError(t, d.SetFrequency(7, 1000))
+	be.Equal(t, sim.Frequency(3), 1000)
+
+	be.AnError(t, d.SetDutyCycle(3, MaxDutyCycle))
+	be.AnError(t, d.SetFrequency(8, 1000))
+}
+
+func TestNew_MissingModule(t *testing.T) {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	sim.SetOptions(1 << seesaw.ModuleNeoPixelBase)
+
+	_, err := New(seesaw.New(seesaw.DefaultSeesawAddress, sim))
+	be.AnError(t, err)
+}
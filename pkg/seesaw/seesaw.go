@@ -9,26 +9,33 @@ import (
 
 const DefaultSeesawAddress = 0x49
 
-// empirically determined standardDelay, the one from the official library seems to be too short (250us)
+// empirically determined delay that always worked, the one from the official library seemed to be too short (250us)
+// on some boards. Adaptive timing never backs off further than this.
 const defaultDelay = 100 * time.Millisecond
 
 const (
-	seesawHwIdCodeSAMD09  = 0x55 // HW ID code for SAMD09
-	seesawHwIdCodeTINY8x7 = 0x87 // HW ID code for ATtiny817
+	HwIdCodeSAMD09  = 0x55 // HW ID code for SAMD09
+	HwIdCodeTINY8x7 = 0x87 // HW ID code for ATtiny817
 )
 
 type Device struct {
-	bus           I2C
-	addr          uint16
-	hwid          byte
-	standardDelay time.Duration
+	bus         I2C
+	addr        uint16
+	hwid        byte
+	options     Options
+	optionsRead bool
+	timing      *Timing
+	adaptive    bool
+	floor       time.Duration
+	adapted     map[timingKey]*adaptedDelay
 }
 
 func New(addr uint16, bus I2C) *Device {
 	return &Device{
-		bus:           bus,
-		addr:          addr,
-		standardDelay: defaultDelay,
+		bus:     bus,
+		addr:    addr,
+		timing:  DefaultTiming(),
+		adapted: make(map[timingKey]*adaptedDelay),
 	}
 }
 
@@ -46,28 +53,40 @@ func (d *Device) waitForReset() error {
 
 	//give the device a little bit of time to reset
 	time.Sleep(time.Second)
+	d.optionsRead = false
 
+	// the chip may still be busy starting up, back off on the read delay until it answers
 	var lastErr error
+	delay := d.readDelay(ModuleStatusBase, FunctionStatusHwId)
 	tries := 0
 	for ; tries < 20; tries++ {
-		hwid, err := d.readHardwareID()
+		hwid, err := d.readHardwareID(delay)
 		if err == nil {
 			d.hwid = hwid
 			return nil
 		}
 		lastErr = err
+		delay = min(2*delay, maxReadDelay)
 		time.Sleep(20 * time.Millisecond)
 	}
 	return errors.New("failed to wait for device to start: " + lastErr.Error())
 }
 
-func (d *Device) readHardwareID() (byte, error) {
-	hwid, err := d.ReadRegister(ModuleStatusBase, FunctionStatusHwId)
+// HardwareID returns the hardware ID of the seesaw chip as read during the last reset, e.g. HwIdCodeSAMD09
+func (d *Device) HardwareID() byte {
+	return d.hwid
+}
+
+func (d *Device) readHardwareID(delay time.Duration) (byte, error) {
+	buf := make([]byte, 1)
+	err := d.ReadWithDelay(ModuleStatusBase, FunctionStatusHwId, buf, delay)
 	if err != nil {
 		return 0, err
 	}
 
-	if hwid == seesawHwIdCodeSAMD09 || hwid == seesawHwIdCodeTINY8x7 {
+	hwid := buf[0]
+
+	if isKnownHardwareID(hwid) {
 		return hwid, nil
 	}
 
@@ -83,16 +102,16 @@ func (d *Device) WriteRegister(module ModuleBaseAddress, function FunctionAddres
 // ReadRegister reads a single register from seesaw
 func (d *Device) ReadRegister(module ModuleBaseAddress, function FunctionAddress) (byte, error) {
 	buf := make([]byte, 1)
-	err := d.Read(module, function, buf, d.standardDelay)
+	err := d.Read(module, function, buf)
 	if err != nil {
 		return 0, err
 	}
 	return buf[0], nil
 }
 
-// Read reads a number of bytes from the device after sending the read command and waiting 'standardDelay'. The delays depend
-// on the module and function and are documented in the seesaw datasheet
-func (d *Device) Read(module ModuleBaseAddress, function FunctionAddress, buf []byte, delay time.Duration) error {
+// ReadWithDelay reads a number of bytes from the device after sending the read command and waiting 'delay'. The delays
+// depend on the module and function and are documented in the seesaw datasheet
+func (d *Device) ReadWithDelay(module ModuleBaseAddress, function FunctionAddress, buf []byte, delay time.Duration) error {
 	prefix := []byte{byte(module), byte(function)}
 	err := d.bus.Tx(d.addr, prefix, nil)
 	if err != nil {
@@ -0,0 +1,301 @@
+// Package seesawsim simulates a seesaw chip behind an I2C bus. It decodes the module/function writes of the seesaw
+// driver and models the status registers, the NeoPixel buffer and the keypad FIFO well enough to unit-test drivers
+// without hardware.
+package seesawsim
+
+import (
+	"errors"
+	"sync"
+	"time"
+	"trelligo/pkg/seesaw"
+)
+
+// ErrNack mimics the error machine.I2C returns if the device does not acknowledge a transfer
+var ErrNack = errors.New("I2C error: expected ACK not NACK")
+
+// DefaultOptions the modules compiled into the NeoTrellis firmware
+const DefaultOptions = 1<<seesaw.ModuleStatusBase | 1<<seesaw.ModuleGpioBase | 1<<seesaw.ModuleTimerBase |
+	1<<seesaw.ModuleAdcBase | 1<<seesaw.ModuleInterruptBase | 1<<seesaw.ModuleEepromBase |
+	1<<seesaw.ModuleNeoPixelBase | 1<<seesaw.ModuleTouchBase | 1<<seesaw.ModuleKeypadBase
+
+// maxNeoPixelPayload the seesaw crashes when a NeoPixel buffer write carries more than 29 data bytes
+const maxNeoPixelPayload = 29
+
+const maxNeoPixelBufferLength = 512
+const eepromSize = 64
+const keyCount = 64
+const fifoSize = 32
+
+// key edges as encoded in the keypad registers
+const (
+	edgeHigh = iota
+	edgeLow
+	edgeFalling
+	edgeRising
+)
+
+// Device is a simulated seesaw chip, it implements the seesaw.I2C interface
+type Device struct {
+	mu sync.Mutex
+
+	addr        uint16
+	hwid        byte
+	version     uint32
+	options     uint32
+	temperature uint32
+
+	// read command sent by the last write, answered by the next read
+	module        seesaw.ModuleBaseAddress
+	function      seesaw.FunctionAddress
+	commandAt     time.Time
+	responseDelay time.Duration
+
+	resets       int
+	transactions int
+	onInterrupt  func()
+
+	pixelPin    uint8
+	pixelSpeed  uint8
+	pixelBuffer []byte
+	pixelShown  []byte
+	shows       int
+
+	keyEdges        [keyCount]uint8
+	keypadInterrupt bool
+	fifo            []byte
+
+	pwm   map[uint8]uint16
+	freq  map[uint8]uint16
+	touch map[uint8]uint16
+
+	eeprom [eepromSize]byte
+}
+
+// New creates a simulated SAMD09 seesaw with the NeoTrellis firmware at the given address
+func New(addr uint16) *Device {
+	d := &Device{
+		addr:        addr,
+		hwid:        seesaw.HwIdCodeSAMD09,
+		version:     3954<<16 | 14<<11 | 6<<7 | 23, // NeoTrellis PID, built 2023-06-14
+		options:     DefaultOptions,
+		temperature: 25 << 16,
+		pwm:         make(map[uint8]uint16),
+		freq:        make(map[uint8]uint16),
+		touch:       make(map[uint8]uint16),
+	}
+	// erased flash
+	for i := range d.eeprom {
+		d.eeprom[i] = 0xFF
+	}
+	d.reset()
+	return d
+}
+
+func (d *Device) reset() {
+	d.pixelPin = 0
+	d.pixelSpeed = 1
+	d.pixelBuffer = d.pixelBuffer[:0]
+	d.pixelShown = d.pixelShown[:0]
+	d.keyEdges = [keyCount]uint8{}
+	d.keypadInterrupt = false
+	d.fifo = d.fifo[:0]
+}
+
+// SetOptions sets the bitmask of modules the simulated firmware supports
+func (d *Device) SetOptions(options seesaw.Options) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.options = uint32(options)
+}
+
+// SetResponseDelay sets the time the simulated chip needs to process a read command, reads arriving earlier are NACKed
+func (d *Device) SetResponseDelay(delay time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.responseDelay = delay
+}
+
+// Tx implements seesaw.I2C
+func (d *Device) Tx(addr uint16, w, r []byte) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if addr != d.addr {
+		return ErrNack
+	}
+	d.transactions++
+	if len(w) > 0 {
+		err := d.write(w)
+		if err != nil {
+			return err
+		}
+	}
+	if len(r) > 0 {
+		return d.read(r)
+	}
+	return nil
+}
+
+func (d *Device) write(w []byte) error {
+	if len(w) < 2 {
+		return ErrNack
+	}
+	d.module = seesaw.ModuleBaseAddress(w[0])
+	d.function = seesaw.FunctionAddress(w[1])
+	d.commandAt = time.Now()
+	data := w[2:]
+
+	switch d.module {
+	case seesaw.ModuleStatusBase:
+		if d.function == seesaw.FunctionStatusSwrst && len(data) > 0 {
+			d.resets++
+			d.reset()
+		}
+	case seesaw.ModuleNeoPixelBase:
+		return d.writeNeoPixel(data)
+	case seesaw.ModuleKeypadBase:
+		return d.writeKeypad(data)
+	case seesaw.ModuleEepromBase:
+		if int(d.function)+len(data) > eepromSize {
+			return ErrNack
+		}
+		copy(d.eeprom[d.function:], data)
+	case seesaw.ModuleTimerBase:
+		if len(data) == 3 {
+			v := uint16(data[1])<<8 | uint16(data[2])
+			if d.function == seesaw.FunctionTimerPwm {
+				d.pwm[data[0]] = v
+			} else if d.function == seesaw.FunctionTimerFreq {
+				d.freq[data[0]] = v
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Device) writeNeoPixel(data []byte) error {
+	switch d.function {
+	case seesaw.FunctionNeopixelPin:
+		if len(data) > 0 {
+			d.pixelPin = data[0]
+		}
+	case seesaw.FunctionNeopixelSpeed:
+		if len(data) > 0 {
+			d.pixelSpeed = data[0]
+		}
+	case seesaw.FunctionNeopixelBufLength:
+		if len(data) < 2 {
+			return ErrNack
+		}
+		n := int(data[0])<<8 | int(data[1])
+		if n > maxNeoPixelBufferLength {
+			return ErrNack
+		}
+		d.pixelBuffer = make([]byte, n)
+		d.pixelShown = make([]byte, n)
+	case seesaw.FunctionNeopixelBuf:
+		if len(data) < 2 || len(data)-2 > maxNeoPixelPayload {
+			return ErrNack
+		}
+		offset := int(data[0])<<8 | int(data[1])
+		if offset < len(d.pixelBuffer) {
+			copy(d.pixelBuffer[offset:], data[2:])
+		}
+	case seesaw.FunctionNeopixelShow:
+		copy(d.pixelShown, d.pixelBuffer)
+		d.shows++
+	}
+	return nil
+}
+
+func (d *Device) writeKeypad(data []byte) error {
+	switch d.function {
+	case seesaw.FunctionKeypadEvent:
+		if len(data) < 2 || data[0] >= keyCount {
+			return ErrNack
+		}
+		key, state := data[0], data[1]
+		edges := state >> 1
+		if state&0x01 != 0 {
+			d.keyEdges[key] |= edges
+		} else {
+			d.keyEdges[key] &^= edges
+		}
+	case seesaw.FunctionKeypadIntenset:
+		d.keypadInterrupt = true
+	case seesaw.FunctionKeypadIntenclr:
+		d.keypadInterrupt = false
+	}
+	return nil
+}
+
+func (d *Device) read(r []byte) error {
+	if time.Since(d.commandAt) < d.responseDelay {
+		return ErrNack
+	}
+
+	switch d.module {
+	case seesaw.ModuleStatusBase:
+		return d.readStatus(r)
+	case seesaw.ModuleKeypadBase:
+		return d.readKeypad(r)
+	case seesaw.ModuleNeoPixelBase:
+		if d.function == seesaw.FunctionNeopixelBuf {
+			copy(r, d.pixelBuffer)
+			return nil
+		}
+	case seesaw.ModuleEepromBase:
+		if int(d.function)+len(r) > eepromSize {
+			return ErrNack
+		}
+		copy(r, d.eeprom[d.function:])
+		return nil
+	case seesaw.ModuleTouchBase:
+		v := d.touch[uint8(d.function-seesaw.FunctionTouchChannelOffset)]
+		putUint32(r, uint32(v)<<16)
+		return nil
+	}
+	return ErrNack
+}
+
+func (d *Device) readStatus(r []byte) error {
+	switch d.function {
+	case seesaw.FunctionStatusHwId:
+		r[0] = d.hwid
+	case seesaw.FunctionStatusVersion:
+		putUint32(r, d.version)
+	case seesaw.FunctionStatusOptions:
+		putUint32(r, d.options)
+	case seesaw.FunctionStatusTemp:
+		putUint32(r, d.temperature)
+	default:
+		return ErrNack
+	}
+	return nil
+}
+
+func (d *Device) readKeypad(r []byte) error {
+	switch d.function {
+	case seesaw.FunctionKeypadCount:
+		// the firmware NACKs the read if the FIFO is empty
+		if len(d.fifo) == 0 {
+			return ErrNack
+		}
+		r[0] = byte(len(d.fifo))
+	case seesaw.FunctionKeypadFifo:
+		n := copy(r, d.fifo)
+		d.fifo = d.fifo[:copy(d.fifo, d.fifo[n:])]
+		for i := n; i < len(r); i++ {
+			r[i] = 0xFF
+		}
+	default:
+		return ErrNack
+	}
+	return nil
+}
+
+func putUint32(r []byte, v uint32) {
+	for i := 0; i < len(r) && i < 4; i++ {
+		r[i] = byte(v >> (24 - 8*i))
+	}
+}
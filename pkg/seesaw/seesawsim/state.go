@@ -0,0 +1,182 @@
+package seesawsim
+
+// Bus connects several simulated devices at different addresses to one I2C bus
+type Bus []*Device
+
+// Tx implements seesaw.I2C, transfers to addresses without a device are NACKed
+func (b Bus) Tx(addr uint16, w, r []byte) error {
+	for _, d := range b {
+		if d.addr == addr {
+			return d.Tx(addr, w, r)
+		}
+	}
+	return ErrNack
+}
+
+// Press simulates pressing the key with the given seesaw key number. A rising edge event is queued if that edge
+// is enabled for the key.
+func (d *Device) Press(key uint8) {
+	d.mu.Lock()
+	interrupt := d.pushEvent(key, edgeRising)
+	d.mu.Unlock()
+	// outside the lock, the callback may read the device
+	if interrupt != nil {
+		interrupt()
+	}
+}
+
+// Release simulates releasing the key with the given seesaw key number. A falling edge event is queued if that edge
+// is enabled for the key.
+func (d *Device) Release(key uint8) {
+	d.mu.Lock()
+	interrupt := d.pushEvent(key, edgeFalling)
+	d.mu.Unlock()
+	// outside the lock, the callback may read the device
+	if interrupt != nil {
+		interrupt()
+	}
+}
+
+// pushEvent queues the event and returns the interrupt callback to invoke, nil if there is none
+func (d *Device) pushEvent(key, edge uint8) func() {
+	if key >= keyCount || d.keyEdges[key]&(1<<edge) == 0 {
+		return nil
+	}
+	if len(d.fifo) >= fifoSize {
+		// like the firmware, drop events if nobody reads them
+		return nil
+	}
+	d.fifo = append(d.fifo, key<<2|edge)
+	if !d.keypadInterrupt {
+		return nil
+	}
+	return d.onInterrupt
+}
+
+// PendingKeyEvents returns the number of key events in the FIFO
+func (d *Device) PendingKeyEvents() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.fifo)
+}
+
+// KeyEventEnabled returns whether events for the given seesaw key and edge are enabled
+func (d *Device) KeyEventEnabled(key, edge uint8) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return key < keyCount && d.keyEdges[key]&(1<<edge) != 0
+}
+
+// InterruptAsserted returns whether the INT line is asserted, i.e. the keypad interrupt is enabled and key events
+// are pending
+func (d *Device) InterruptAsserted() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.keypadInterrupt && len(d.fifo) > 0
+}
+
+// IntPin is the simulated active-low INT line of a Device
+type IntPin struct {
+	dev *Device
+}
+
+// IntPin returns the INT line of the device, it implements the Get method of machine.Pin
+func (d *Device) IntPin() IntPin {
+	return IntPin{dev: d}
+}
+
+func (p IntPin) Get() bool {
+	return !p.dev.InterruptAsserted()
+}
+
+// SetInterruptFunc sets a callback invoked whenever INT is asserted for a new key event, like a pin-change
+// interrupt on the falling edge. It runs on the goroutine pressing the key and may read the device.
+func (d *Device) SetInterruptFunc(f func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.onInterrupt = f
+}
+
+// Transactions returns the number of I2C transfers addressed to the device
+func (d *Device) Transactions() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.transactions
+}
+
+// KeypadInterruptEnabled returns whether the keypad interrupt is enabled
+func (d *Device) KeypadInterruptEnabled() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.keypadInterrupt
+}
+
+// PixelPin returns the configured NeoPixel pin
+func (d *Device) PixelPin() uint8 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pixelPin
+}
+
+// PixelSpeed returns the configured NeoPixel speed, 1 for 800kHz and 0 for 400kHz
+func (d *Device) PixelSpeed() uint8 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pixelSpeed
+}
+
+// PixelBuffer returns a copy of the NeoPixel buffer as written, including changes not shown yet
+func (d *Device) PixelBuffer() []byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]byte(nil), d.pixelBuffer...)
+}
+
+// ShownPixels returns a copy of the NeoPixel buffer as of the last show command, i.e. what the LEDs display
+func (d *Device) ShownPixels() []byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]byte(nil), d.pixelShown...)
+}
+
+// Shows returns the number of show commands received
+func (d *Device) Shows() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.shows
+}
+
+// Resets returns the number of soft-resets received
+func (d *Device) Resets() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.resets
+}
+
+// DutyCycle returns the duty cycle of a PWM channel
+func (d *Device) DutyCycle(channel uint8) uint16 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.pwm[channel]
+}
+
+// Frequency returns the frequency of a PWM channel
+func (d *Device) Frequency(channel uint8) uint16 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.freq[channel]
+}
+
+// EEPROM returns a copy of the EEPROM, it survives soft-resets
+func (d *Device) EEPROM() []byte {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]byte(nil), d.eeprom[:]...)
+}
+
+// SetTouch sets the raw value of a touch channel
+func (d *Device) SetTouch(channel uint8, value uint16) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.touch[channel] = value
+}
@@ -0,0 +1,214 @@
+package seesaw
+
+import (
+	"errors"
+	"time"
+)
+
+// Read delays as given by the seesaw datasheet and the official library. The seesaw needs this time between
+// receiving a read command and being able to answer it.
+const (
+	defaultReadDelay     = 250 * time.Microsecond
+	tempReadDelay        = time.Millisecond
+	adcReadDelay         = 500 * time.Microsecond
+	keypadCountReadDelay = 500 * time.Microsecond
+	keypadReadDelay      = 2 * time.Millisecond
+	touchReadDelay       = 3 * time.Millisecond
+)
+
+// maxReadDelay upper bound when backing off, this is the empirically determined delay that always worked
+const maxReadDelay = defaultDelay
+
+// adaptive timing tuning: shrink by 1/shrinkDivisor after shrinkAfter successful reads in a row
+const (
+	shrinkAfter   = 16
+	shrinkDivisor = 8
+)
+
+type timingKey uint16
+
+func newTimingKey(module ModuleBaseAddress, function FunctionAddress) timingKey {
+	return timingKey(module)<<8 | timingKey(function)
+}
+
+// Timing is a table of read delays per module and function
+type Timing struct {
+	fallback time.Duration
+	delays   map[timingKey]time.Duration
+}
+
+// NewTiming creates a table where all reads use the given delay
+func NewTiming(fallback time.Duration) *Timing {
+	return &Timing{
+		fallback: fallback,
+		delays:   make(map[timingKey]time.Duration),
+	}
+}
+
+// DefaultTiming creates a table with the read delays from the seesaw datasheet
+func DefaultTiming() *Timing {
+	t := NewTiming(defaultReadDelay)
+	t.Set(ModuleStatusBase, FunctionStatusTemp, tempReadDelay)
+	for ch := FunctionAdcChannelOffset; ch < FunctionAdcChannelOffset+8; ch++ {
+		t.Set(ModuleAdcBase, ch, adcReadDelay)
+	}
+	t.Set(ModuleKeypadBase, FunctionKeypadCount, keypadCountReadDelay)
+	t.Set(ModuleKeypadBase, FunctionKeypadFifo, keypadReadDelay)
+	for ch := FunctionTouchChannelOffset; ch < FunctionTouchChannelOffset+4; ch++ {
+		t.Set(ModuleTouchBase, ch, touchReadDelay)
+	}
+	return t
+}
+
+// Set sets the read delay of a module and function
+func (t *Timing) Set(module ModuleBaseAddress, function FunctionAddress, delay time.Duration) {
+	t.delays[newTimingKey(module, function)] = delay
+}
+
+// Delay returns the read delay of a module and function
+func (t *Timing) Delay(module ModuleBaseAddress, function FunctionAddress) time.Duration {
+	if d, ok := t.delays[newTimingKey(module, function)]; ok {
+		return d
+	}
+	return t.fallback
+}
+
+type adaptedDelay struct {
+	delay  time.Duration
+	streak uint8
+}
+
+// SetTiming replaces the table of read delays
+func (d *Device) SetTiming(t *Timing) {
+	d.timing = t
+	d.adapted = make(map[timingKey]*adaptedDelay)
+}
+
+// Timing returns the table of read delays
+func (d *Device) Timing() *Timing {
+	return d.timing
+}
+
+// SetAdaptiveTiming enables or disables adaptive read delays. When enabled, every function starts with the delay
+// from the table. Failed reads are retried with doubled delays until they succeed or the delay reaches the
+// empirically safe 100ms, and shrink back to the table while reads succeed. Only reads that are checked, the
+// hardware ID, go below the table, down to the minimum found by Calibrate, so Calibrate should be called first.
+func (d *Device) SetAdaptiveTiming(enable bool) {
+	d.adaptive = enable
+	d.adapted = make(map[timingKey]*adaptedDelay)
+}
+
+// Calibrate searches the shortest delay at which the hardware ID reads back correctly samples times in a row. No
+// read uses a shorter delay (plus some headroom) afterwards, the table only ever slows down reads. Returns the new
+// minimum delay.
+func (d *Device) Calibrate(samples int) (time.Duration, error) {
+	buf := make([]byte, 1)
+	reliable := func(delay time.Duration) bool {
+		for i := 0; i < samples; i++ {
+			err := d.ReadWithDelay(ModuleStatusBase, FunctionStatusHwId, buf, delay)
+			if err != nil || !isKnownHardwareID(buf[0]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// find a reliable upper bound, then bisect towards the last failing delay
+	lo := time.Duration(0)
+	hi := d.timing.Delay(ModuleStatusBase, FunctionStatusHwId)
+	for !reliable(hi) {
+		if hi >= maxReadDelay {
+			return 0, errors.New("no reliable read delay up to " + maxReadDelay.String())
+		}
+		lo = hi
+		hi = min(2*hi, maxReadDelay)
+	}
+	for hi-lo > 10*time.Microsecond {
+		mid := lo + (hi-lo)/2
+		if reliable(mid) {
+			hi = mid
+		} else {
+			lo = mid
+		}
+	}
+
+	// leave some headroom, the bisection ends right at the edge of what works
+	d.floor = hi + hi/4
+	d.adapted = make(map[timingKey]*adaptedDelay)
+	return d.floor, nil
+}
+
+// Read reads a number of bytes from the device after sending the read command and waiting for the delay
+// from the timing table.
+func (d *Device) Read(module ModuleBaseAddress, function FunctionAddress, buf []byte) error {
+	if !d.adaptive {
+		return d.ReadWithDelay(module, function, buf, d.readDelay(module, function))
+	}
+
+	key := newTimingKey(module, function)
+	a := d.adapted[key]
+	if a == nil {
+		a = &adaptedDelay{delay: d.readDelay(module, function)}
+		d.adapted[key] = a
+	}
+
+	// retry with growing delays until the read succeeds or the delay is maxed out
+	for {
+		err := d.ReadWithDelay(module, function, buf, a.delay)
+		if err == nil && checked(module, function) && !isKnownHardwareID(buf[0]) {
+			err = errors.New("unexpected hardware ID read back: 0x" + byteToHexString(buf[0]))
+		}
+		if err == nil {
+			a.succeeded(d.lowestDelay(module, function))
+			return nil
+		}
+		if a.delay >= maxReadDelay {
+			return err
+		}
+		a.failed()
+	}
+}
+
+// ReadDelay returns the delay the next read of a module and function waits for
+func (d *Device) ReadDelay(module ModuleBaseAddress, function FunctionAddress) time.Duration {
+	if a := d.adapted[newTimingKey(module, function)]; d.adaptive && a != nil {
+		return a.delay
+	}
+	return d.readDelay(module, function)
+}
+
+func (d *Device) readDelay(module ModuleBaseAddress, function FunctionAddress) time.Duration {
+	return max(d.timing.Delay(module, function), d.floor)
+}
+
+// lowestDelay returns how far adaptive timing may shrink the delay. Reads that return garbage instead of failing
+// would pass as successful, so only checked reads go below the table and only once Calibrate found a floor.
+func (d *Device) lowestDelay(module ModuleBaseAddress, function FunctionAddress) time.Duration {
+	if d.floor > 0 && checked(module, function) {
+		return d.floor
+	}
+	return d.readDelay(module, function)
+}
+
+// checked returns whether the result of a read is checked, which is only the case for the hardware ID
+func checked(module ModuleBaseAddress, function FunctionAddress) bool {
+	return module == ModuleStatusBase && function == FunctionStatusHwId
+}
+
+func (a *adaptedDelay) succeeded(floor time.Duration) {
+	a.streak++
+	if a.streak < shrinkAfter {
+		return
+	}
+	a.streak = 0
+	a.delay = max(a.delay-a.delay/shrinkDivisor, floor)
+}
+
+func (a *adaptedDelay) failed() {
+	a.streak = 0
+	a.delay = min(max(2*a.delay, 50*time.Microsecond), maxReadDelay)
+}
+
+func isKnownHardwareID(hwid byte) bool {
+	return hwid == HwIdCodeSAMD09 || hwid == HwIdCodeTINY8x7
+}
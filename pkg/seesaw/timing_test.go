@@ -0,0 +1,82 @@
+package seesaw_test
+
+import (
+	"testing"
+	"time"
+	"trelligo/pkg/be"
+	"trelligo/pkg/seesaw"
+	"trelligo/pkg/seesaw/seesawsim"
+)
+
+func newTestDevice(responseDelay time.Duration) *seesaw.Device {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	sim.SetResponseDelay(responseDelay)
+	return seesaw.New(seesaw.DefaultSeesawAddress, sim)
+}
+
+func TestDefaultTiming(t *testing.T) {
+	timing := seesaw.DefaultTiming()
+
+	be.Equal(t, timing.Delay(seesaw.ModuleStatusBase, seesaw.FunctionStatusHwId), 250*time.Microsecond)
+	be.Equal(t, timing.Delay(seesaw.ModuleStatusBase, seesaw.FunctionStatusTemp), time.Millisecond)
+	be.Equal(t, timing.Delay(seesaw.ModuleTouchBase, seesaw.FunctionTouchChannelOffset+3), 3*time.Millisecond)
+	be.Equal(t, timing.Delay(seesaw.ModuleKeypadBase, seesaw.FunctionKeypadFifo), 2*time.Millisecond)
+}
+
+func TestDevice_AdaptiveTiming_BacksOff(t *testing.T) {
+	dev := newTestDevice(20 * time.Millisecond)
+
+	_, err := dev.ReadRegister(seesaw.ModuleStatusBase, seesaw.FunctionStatusHwId)
+	be.AnError(t, err)
+
+	dev.SetAdaptiveTiming(true)
+	hwid, err := dev.ReadRegister(seesaw.ModuleStatusBase, seesaw.FunctionStatusHwId)
+	be.NoError(t, err)
+	be.Equal(t, hwid, seesaw.HwIdCodeSAMD09)
+
+	delay := dev.ReadDelay(seesaw.ModuleStatusBase, seesaw.FunctionStatusHwId)
+	if delay < 10*time.Millisecond {
+		t.Fatalf("expected delay to back off to at least 10ms, got: %s", delay)
+	}
+}
+
+func TestDevice_AdaptiveTiming_Shrinks(t *testing.T) {
+	dev := newTestDevice(0)
+	dev.SetAdaptiveTiming(true)
+
+	readMany := func(function seesaw.FunctionAddress) time.Duration {
+		buf := make([]byte, 4)
+		for i := 0; i < 64; i++ {
+			be.NoError(t, dev.Read(seesaw.ModuleStatusBase, function, buf[:1]))
+		}
+		return dev.ReadDelay(seesaw.ModuleStatusBase, function)
+	}
+	table := dev.Timing().Delay(seesaw.ModuleStatusBase, seesaw.FunctionStatusHwId)
+
+	// without a calibrated floor nothing goes below the table
+	be.Equal(t, readMany(seesaw.FunctionStatusHwId), table)
+	be.Equal(t, readMany(seesaw.FunctionStatusVersion), table)
+
+	// the hardware ID is checked, so it may shrink down to the floor, unchecked reads stay on the table
+	_, err := dev.Calibrate(3)
+	be.NoError(t, err)
+	if delay := readMany(seesaw.FunctionStatusHwId); delay >= table {
+		t.Fatalf("expected delay to shrink below %s, got: %s", table, delay)
+	}
+	be.Equal(t, readMany(seesaw.FunctionStatusVersion), table)
+}
+
+func TestDevice_Calibrate(t *testing.T) {
+	dev := newTestDevice(20 * time.Millisecond)
+
+	// sleeping overshoots a bit, allow some slack towards shorter delays
+	delay, err := dev.Calibrate(3)
+	be.NoError(t, err)
+	if delay < 10*time.Millisecond || delay > 40*time.Millisecond {
+		t.Fatalf("expected a delay between 10ms and 40ms, got: %s", delay)
+	}
+
+	// all reads respect the calibrated delay now
+	_, err = dev.ReadRegister(seesaw.ModuleStatusBase, seesaw.FunctionStatusHwId)
+	be.NoError(t, err)
+}
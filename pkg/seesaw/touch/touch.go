@@ -0,0 +1,136 @@
+// Package touch reads the capacitive touch inputs of the seesaw touch module.
+package touch
+
+import (
+	"errors"
+	"strconv"
+	"time"
+	"trelligo/pkg/seesaw"
+)
+
+// readRetries the firmware does not always have a measurement ready, the official library retries a few times
+// with increasing delays
+const readRetries = 5
+
+// defaultMargin is added to the calibrated baseline to get the touch threshold, roughly what a finger adds
+// on a small pad
+const defaultMargin = 200
+
+type Edge uint8
+
+const (
+	EdgeTouched Edge = iota
+	EdgeReleased
+)
+
+type Sensor struct {
+	seesaw     *seesaw.Device
+	channel    uint8
+	threshold  uint16
+	hysteresis uint16
+	touched    bool
+	handler    func(e Edge) error
+}
+
+// New creates a sensor for the given touch channel. The sensor is uncalibrated, Calibrate should be called while
+// the pad is not touched.
+func New(dev *seesaw.Device, channel uint8) (*Sensor, error) {
+	err := dev.RequireModule(seesaw.ModuleTouchBase)
+	if err != nil {
+		return nil, err
+	}
+	return &Sensor{
+		seesaw:     dev,
+		channel:    channel,
+		threshold:  0xFFFF,
+		hysteresis: defaultMargin / 4,
+	}, nil
+}
+
+// ReadRaw reads the raw capacitance value of the channel, higher values mean more capacitance
+func (s *Sensor) ReadRaw() (uint16, error) {
+	//https://github.com/adafruit/Adafruit_Seesaw/blob/master/Adafruit_seesaw.cpp#L451
+	buf := make([]byte, 2)
+	var lastErr error
+	function := seesaw.FunctionTouchChannelOffset + seesaw.FunctionAddress(s.channel)
+	delay := s.seesaw.ReadDelay(seesaw.ModuleTouchBase, function)
+	for i := 0; i < readRetries; i++ {
+		err := s.seesaw.ReadWithDelay(seesaw.ModuleTouchBase, function, buf, delay+time.Duration(i)*time.Millisecond)
+		if err == nil {
+			return uint16(buf[0])<<8 | uint16(buf[1]), nil
+		}
+		lastErr = err
+	}
+	return 0, errors.New("failed to read touch channel " + strconv.Itoa(int(s.channel)) + ": " + lastErr.Error())
+}
+
+// Calibrate averages a number of readings of the untouched pad and sets the threshold to the average plus margin.
+// A margin of 0 uses a default.
+func (s *Sensor) Calibrate(samples int, margin uint16) error {
+	if samples <= 0 {
+		return errors.New("invalid sample count: " + strconv.Itoa(samples))
+	}
+	if margin == 0 {
+		margin = defaultMargin
+	}
+
+	var sum uint32
+	for i := 0; i < samples; i++ {
+		v, err := s.ReadRaw()
+		if err != nil {
+			return err
+		}
+		sum += uint32(v)
+	}
+
+	baseline := sum / uint32(samples)
+	s.SetThreshold(uint16(min(baseline+uint32(margin), 0xFFFF)), margin/4)
+	return nil
+}
+
+// SetThreshold sets the raw value above which the pad counts as touched. It is released again once the value falls
+// below threshold-hysteresis.
+func (s *Sensor) SetThreshold(threshold, hysteresis uint16) {
+	s.threshold = threshold
+	s.hysteresis = min(hysteresis, threshold)
+}
+
+// Touched returns whether the pad was touched during the last call to Process
+func (s *Sensor) Touched() bool {
+	return s.touched
+}
+
+// SetEdgeHandleFunc sets a callback for touch and release events
+//
+// Note: In order for the handler to be called, the sensor MUST be processed via Process.
+func (s *Sensor) SetEdgeHandleFunc(handler func(e Edge) error) {
+	s.handler = handler
+}
+
+// Process reads the sensor and calls the handler if the pad was touched or released since the last call
+func (s *Sensor) Process() error {
+	v, err := s.ReadRaw()
+	if err != nil {
+		return err
+	}
+
+	touched := s.touched
+	if v >= s.threshold {
+		touched = true
+	} else if v < s.threshold-s.hysteresis {
+		touched = false
+	}
+
+	if touched == s.touched {
+		return nil
+	}
+	s.touched = touched
+
+	if s.handler == nil {
+		return nil
+	}
+	if touched {
+		return s.handler(EdgeTouched)
+	}
+	return s.handler(EdgeReleased)
+}
@@ -0,0 +1,59 @@
+package touch
+
+import (
+	"testing"
+	"trelligo/pkg/be"
+	"trelligo/pkg/seesaw"
+	"trelligo/pkg/seesaw/seesawsim"
+)
+
+func newTestSensor(t *testing.T) (*Sensor, *seesawsim.Device) {
+	sim := seesawsim.New(seesaw.DefaultSeesawAddress)
+	s, err := New(seesaw.New(seesaw.DefaultSeesawAddress, sim), 2)
+	be.NoError(t, err)
+	return s, sim
+}
+
+func TestSensor_Calibrate(t *testing.T) {
+	s, sim := newTestSensor(t)
+
+	sim.SetTouch(2, 400)
+	be.NoError(t, s.Calibrate(4, 0))
+	be.Equal(t, s.threshold, 400+defaultMargin)
+	be.Equal(t, s.hysteresis, defaultMargin/4)
+
+	// the threshold saturates instead of wrapping around
+	sim.SetTouch(2, 0xFFF0)
+	be.NoError(t, s.Calibrate(1, 100))
+	be.Equal(t, s.threshold, 0xFFFF)
+
+	be.AnError(t, s.Calibrate(0, 0))
+}
+
+func TestSensor_Process(t *testing.T) {
+	s, sim := newTestSensor(t)
+	var edges []Edge
+	s.SetEdgeHandleFunc(func(e Edge) error {
+		edges = append(edges, e)
+		return nil
+	})
+	s.SetThreshold(600, 100)
+
+	sim.SetTouch(2, 650)
+	be.NoError(t, s.Process())
+	be.Equal(t, s.Touched(), true)
+
+	// within the hysteresis the pad stays touched
+	sim.SetTouch(2, 550)
+	be.NoError(t, s.Process())
+	be.Equal(t, s.Touched(), true)
+
+	sim.SetTouch(2, 450)
+	be.NoError(t, s.Process())
+	be.Equal(t, s.Touched(), false)
+
+	be.NoError(t, s.Process())
+	be.Equal(t, len(edges), 2)
+	be.Equal(t, edges[0], EdgeTouched)
+	be.Equal(t, edges[1], EdgeReleased)
+}
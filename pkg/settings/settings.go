@@ -0,0 +1,173 @@
+// Package settings persists the player settings, e.g. in the EEPROM of the seesaw.
+//
+// The encoding is kept small, the seesaw EEPROM only has 63 usable bytes:
+//
+//	[magic] [version] [payload...] [checksum]
+package settings
+
+import (
+	"errors"
+	"io"
+	"strconv"
+)
+
+const magic = 0x7E
+
+// Version of the encoding, newer versions only append to the payload
+const Version = 2
+
+// KeyCount is the number of keys of a NeoTrellis
+const KeyCount = 16
+
+// keysLen two bytes per key, action and color nibbles followed by the argument
+const keysLen = 2 * KeyCount
+
+// volumeLen since version 2
+const volumeLen = 8
+
+// Len is the number of bytes of the encoded settings
+const Len = 2 + keysLen + volumeLen + 1
+
+// ErrNoSettings is returned when nothing valid is stored, e.g. on a new device
+var ErrNoSettings = errors.New("settings: no valid settings stored")
+
+// Key is the persisted form of a key, Action and Color must fit into 4 bits each
+type Key struct {
+	Action uint8
+	Arg    uint8
+	Color  uint8
+}
+
+// Volume is the persisted form of the volume limits, all zero if they were never saved
+type Volume struct {
+	DayMin     uint8
+	DayMax     uint8
+	DayCurve   uint8
+	NightMin   uint8
+	NightMax   uint8
+	NightCurve uint8
+	NightFrom  uint8
+	NightUntil uint8
+}
+
+type Settings struct {
+	Keys   [KeyCount]Key
+	Volume Volume
+}
+
+// lenOf returns the number of encoded bytes of the version
+func lenOf(version uint8) int {
+	if version < 2 {
+		return 2 + keysLen + 1
+	}
+	return Len
+}
+
+// Marshal encodes the settings including magic and checksum
+func (s *Settings) Marshal() ([]byte, error) {
+	b := make([]byte, Len)
+	b[0] = magic
+	b[1] = Version
+	for i, k := range s.Keys {
+		if k.Action > 0xF || k.Color > 0xF {
+			return nil, errors.New("settings: key " + strconv.Itoa(i) + " does not fit into 4 bits")
+		}
+		b[2+2*i] = k.Action<<4 | k.Color
+		b[3+2*i] = k.Arg
+	}
+	v := s.Volume
+	copy(b[2+keysLen:], []byte{v.DayMin, v.DayMax, v.DayCurve, v.NightMin, v.NightMax, v.NightCurve, v.NightFrom, v.NightUntil})
+	b[Len-1] = checksum(b[:Len-1])
+	return b, nil
+}
+
+// Unmarshal decodes the settings, ErrNoSettings is returned for anything that wasn't written by Marshal.
+// Fields older versions didn't have are zero.
+func (s *Settings) Unmarshal(b []byte) error {
+	if len(b) < 2 || b[0] != magic || b[1] == 0 || b[1] > Version {
+		return ErrNoSettings
+	}
+	n := lenOf(b[1])
+	if len(b) < n || checksum(b[:n-1]) != b[n-1] {
+		return ErrNoSettings
+	}
+	for i := range s.Keys {
+		s.Keys[i] = Key{
+			Action: b[2+2*i] >> 4,
+			Color:  b[2+2*i] & 0xF,
+			Arg:    b[3+2*i],
+		}
+	}
+	s.Volume = Volume{}
+	if b[1] >= 2 {
+		v := b[2+keysLen:]
+		s.Volume = Volume{
+			DayMin: v[0], DayMax: v[1], DayCurve: v[2],
+			NightMin: v[3], NightMax: v[4], NightCurve: v[5],
+			NightFrom: v[6], NightUntil: v[7],
+		}
+	}
+	return nil
+}
+
+// checksum is a simple rotating xor, good enough to detect erased or foreign data
+func checksum(b []byte) uint8 {
+	c := uint8(0xA5)
+	for _, v := range b {
+		c = (c<<1 | c>>7) ^ v
+	}
+	return c
+}
+
+type ReadWriterAt interface {
+	io.ReaderAt
+	io.WriterAt
+}
+
+// Store loads and saves settings at the start of the given storage
+type Store struct {
+	rw ReadWriterAt
+}
+
+func NewStore(rw ReadWriterAt) *Store {
+	return &Store{rw: rw}
+}
+
+func (st *Store) Load() (Settings, error) {
+	var s Settings
+	b := make([]byte, Len)
+	_, err := st.rw.ReadAt(b, 0)
+	if err != nil {
+		return s, err
+	}
+	err = s.Unmarshal(b)
+	return s, err
+}
+
+// Save only writes the bytes that changed, EEPROMs wear out
+func (st *Store) Save(s Settings) error {
+	b, err := s.Marshal()
+	if err != nil {
+		return err
+	}
+	old := make([]byte, Len)
+	_, err = st.rw.ReadAt(old, 0)
+	if err != nil {
+		return err
+	}
+
+	first, last := -1, -1
+	for i := range b {
+		if b[i] != old[i] {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+	_, err = st.rw.WriteAt(b[first:last+1], int64(first))
+	return err
+}
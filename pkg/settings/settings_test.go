@@ -0,0 +1,71 @@
+package settings
+
+import (
+	"testing"
+	"trelligo/pkg/be"
+)
+
+type memory struct {
+	data   [63]byte
+	writes int
+}
+
+func (m *memory) ReadAt(p []byte, off int64) (int, error) {
+	return copy(p, m.data[off:]), nil
+}
+
+func (m *memory) WriteAt(p []byte, off int64) (int, error) {
+	m.writes++
+	return copy(m.data[off:], p), nil
+}
+
+func TestStore(t *testing.T) {
+	m := &memory{}
+	for i := range m.data {
+		m.data[i] = 0xFF
+	}
+	st := NewStore(m)
+
+	_, err := st.Load()
+	be.Equal(t, err, ErrNoSettings)
+
+	var s Settings
+	s.Keys[3] = Key{Action: 1, Arg: 4, Color: 2}
+	s.Keys[15] = Key{Action: 15, Arg: 0xFF, Color: 15}
+	s.Volume = Volume{DayMin: 2, DayMax: 20, NightMax: 10, NightFrom: 19, NightUntil: 7}
+	be.NoError(t, st.Save(s))
+	be.Equal(t, m.writes, 1)
+
+	loaded, err := st.Load()
+	be.NoError(t, err)
+	be.Equal(t, loaded, s)
+
+	// unchanged settings aren't written again
+	be.NoError(t, st.Save(s))
+	be.Equal(t, m.writes, 1)
+
+	m.data[5] ^= 1
+	_, err = st.Load()
+	be.Equal(t, err, ErrNoSettings)
+}
+
+func TestSettings_Marshal(t *testing.T) {
+	var s Settings
+	s.Keys[0].Action = 16
+	_, err := s.Marshal()
+	be.AnError(t, err)
+}
+
+func TestSettings_UnmarshalVersion1(t *testing.T) {
+	b := make([]byte, Len)
+	b[0], b[1] = magic, 1
+	b[2] = 1<<4 | 2
+	b[3] = 9
+	n := lenOf(1)
+	b[n-1] = checksum(b[:n-1])
+
+	var s Settings
+	be.NoError(t, s.Unmarshal(b))
+	be.Equal(t, s.Keys[0], Key{Action: 1, Arg: 9, Color: 2})
+	be.Equal(t, s.Volume, Volume{})
+}